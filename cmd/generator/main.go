@@ -2,8 +2,10 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -19,7 +21,23 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
+	generateCmd.Flags().Bool("dry-run", false, "print files that would be created or modified without writing them")
+	generateCmd.Flags().Bool("diff", false, "print a unified diff against the current tree without writing files")
+	generateCmd.Flags().Bool("force", false, "overwrite files with merge conflicts instead of keeping the current version")
+	generateCmd.Flags().String("templates", "", "directory with templates overriding the built-in ones")
+	generateCmd.Flags().Bool("verify", false, "format the generated Go files and type-check the written project")
+	templatesExportCmd.Flags().Bool("force", false, "overwrite existing files")
+	validateCmd.Flags().String("format", "text", "output format: text or json")
+	statusCmd.Flags().String("format", "text", "output format: text or json")
+	statusCmd.Flags().Bool("strict", false, "treat locally modified generated files as drift")
+	statusCmd.Flags().String("templates", "", "directory with templates overriding the built-in ones, as passed to generate")
+
+	rootCmd.Version = usecase.Version
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(templatesCmd)
+	templatesCmd.AddCommand(templatesExportCmd)
 }
 
 var generateCmd = &cobra.Command{
@@ -27,25 +45,34 @@ var generateCmd = &cobra.Command{
 	Short: "Generate CRUD project from JSON configuration",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		jsonFile := args[0]
-
-		// Читаем конфигурационный файл
-		data, err := os.ReadFile(jsonFile)
-		if err != nil {
-			fmt.Printf("Error reading config file: %v\n", err)
-			os.Exit(1)
+		config, data := loadConfig(args[0])
+		if dir, _ := cmd.Flags().GetString("templates"); dir != "" {
+			config.Templates = dir
 		}
 
-		// Парсим конфигурацию
-		var config domain.ProjectConfig
-		if err := json.Unmarshal(data, &config); err != nil {
-			fmt.Printf("Error parsing config file: %v\n", err)
+		// Проверяем конфигурацию
+		diagnostics := usecase.NewValidator().ValidateRaw(data)
+		for _, d := range diagnostics {
+			fmt.Println(usecase.FormatDiagnostic(d))
+		}
+		if usecase.HasErrors(diagnostics) {
+			fmt.Println("Конфигурация содержит ошибки, генерация прервана")
 			os.Exit(1)
 		}
 
 		// Создаем генератор
 		generator := usecase.NewGenerator()
 
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		showDiff, _ := cmd.Flags().GetBool("diff")
+		if dryRun || showDiff {
+			if err := preview(generator, config, showDiff); err != nil {
+				fmt.Printf("Ошибка генерации проекта: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Генерируем проект
 		fmt.Println("Генерация проекта...")
 		progress := 0
@@ -55,14 +82,231 @@ var generateCmd = &cobra.Command{
 			time.Sleep(100 * time.Millisecond)
 		}
 		fmt.Println()
-		if err := generator.Generate(&config); err != nil {
+		force, _ := cmd.Flags().GetBool("force")
+		verify, _ := cmd.Flags().GetBool("verify")
+		files, err := generator.Render(config)
+		if err != nil {
 			fmt.Printf("Ошибка генерации проекта: %v\n", err)
 			os.Exit(1)
 		}
+		changes, err := generator.Write(files, force)
+		var conflictErr *usecase.ConflictError
+		if errors.As(err, &conflictErr) {
+			for _, change := range changes {
+				if change.Status == domain.FileConflict {
+					path := filepath.Join(files.Root, change.Path)
+					fmt.Printf("Конфликт: %s: %s, результат слияния сохранен в %s.conflict\n", path, change.Reason, path)
+				}
+			}
+			fmt.Println("Файлы с конфликтами не изменены, разрешите конфликты вручную или запустите генерацию с --force")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Ошибка генерации проекта: %v\n", err)
+			os.Exit(1)
+		}
+		if verify {
+			fmt.Println("Проверка сгенерированного кода...")
+			typeErrors, err := generator.Verify(files)
+			if err != nil {
+				fmt.Printf("Ошибка проверки проекта: %v\n", err)
+				os.Exit(1)
+			}
+			for _, e := range typeErrors {
+				fmt.Println(usecase.FormatBuildError(e))
+			}
+			if len(typeErrors) > 0 {
+				fmt.Printf("Проект %s сгенерирован, но не компилируется: ошибок %d\n", config.Name, len(typeErrors))
+				os.Exit(1)
+			}
+		}
 		fmt.Printf("✨ Проект %s успешно сгенерирован! ✨\n", config.Name)
 	},
 }
 
+var validateCmd = &cobra.Command{
+	Use:   "validate [json-file]",
+	Short: "Validate JSON configuration without generating code",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			fmt.Printf("Unknown format %q, use text or json\n", format)
+			os.Exit(2)
+		}
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Printf("Error reading config file: %v\n", err)
+			os.Exit(1)
+		}
+
+		diagnostics := usecase.NewValidator().ValidateRaw(data)
+		valid := !usecase.HasErrors(diagnostics)
+
+		if format == "json" {
+			if diagnostics == nil {
+				diagnostics = []domain.Diagnostic{}
+			}
+			out, _ := json.MarshalIndent(struct {
+				Valid       bool                `json:"valid"`
+				Diagnostics []domain.Diagnostic `json:"diagnostics"`
+			}{valid, diagnostics}, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			for _, d := range diagnostics {
+				fmt.Println(usecase.FormatDiagnostic(d))
+			}
+			if valid {
+				fmt.Println("Конфигурация корректна")
+			}
+		}
+
+		if !valid {
+			os.Exit(1)
+		}
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status [json-file]",
+	Short: "Compare generated files with the lock file and detect drift",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		strict, _ := cmd.Flags().GetBool("strict")
+		config, _ := loadConfig(args[0])
+		if dir, _ := cmd.Flags().GetString("templates"); dir != "" {
+			config.Templates = dir
+		}
+
+		report, err := usecase.NewGenerator().Status(config)
+		if err != nil {
+			fmt.Printf("Error reading lock file: %v\n", err)
+			os.Exit(1)
+		}
+
+		drift := !report.Locked || report.ConfigChanged
+		for _, file := range report.Files {
+			if file.State == domain.FileMissing || (strict && file.State == domain.FileEdited) {
+				drift = true
+			}
+		}
+
+		if format == "json" {
+			out, _ := json.MarshalIndent(struct {
+				*domain.StatusReport
+				Drift bool `json:"drift"`
+			}{report, drift}, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			switch {
+			case !report.Locked:
+				fmt.Printf("Lock-файл в %s не найден, проект еще не генерировался\n", config.Name)
+			case report.ConfigChanged:
+				fmt.Println("Конфигурация изменилась после последней генерации")
+			}
+			if report.Locked && report.GeneratorVersion != usecase.Version {
+				fmt.Printf("Проект сгенерирован версией %s, текущая версия %s\n", report.GeneratorVersion, usecase.Version)
+			}
+			for _, file := range report.Files {
+				if file.State != domain.FilePristine {
+					fmt.Printf("%10s  %s\n", file.State, filepath.Join(config.Name, file.Path))
+				}
+			}
+			if !drift {
+				fmt.Println("Сгенерированный код соответствует конфигурации")
+			}
+		}
+
+		if drift {
+			os.Exit(1)
+		}
+	},
+}
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage code templates",
+}
+
+var templatesExportCmd = &cobra.Command{
+	Use:   "export [dir]",
+	Short: "Export built-in templates as a starting point for overrides",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "templates"
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		force, _ := cmd.Flags().GetBool("force")
+
+		written, err := usecase.ExportTemplates(dir, force)
+		if err != nil {
+			fmt.Printf("Error exporting templates: %v\n", err)
+			os.Exit(1)
+		}
+		for _, path := range written {
+			fmt.Println(path)
+		}
+		fmt.Printf("Экспортировано шаблонов: %d\n", len(written))
+	},
+}
+
+// loadConfig читает и разбирает конфигурационный файл. Относительный путь
+// к каталогу шаблонов отсчитывается от расположения конфигурации
+func loadConfig(path string) (*domain.ProjectConfig, []byte) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error reading config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	var config domain.ProjectConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		fmt.Printf("Error parsing config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	if config.Templates != "" && !filepath.IsAbs(config.Templates) {
+		config.Templates = filepath.Join(filepath.Dir(path), config.Templates)
+	}
+
+	return &config, data
+}
+
+// preview выводит план изменений, ничего не записывая на диск
+func preview(generator usecase.Generator, config *domain.ProjectConfig, showDiff bool) error {
+	files, err := generator.Render(config)
+	if err != nil {
+		return err
+	}
+
+	changes, err := generator.Plan(files, showDiff)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Status]++
+		if showDiff {
+			fmt.Print(change.Diff)
+			continue
+		}
+		line := fmt.Sprintf("%10s  %s", change.Status, filepath.Join(files.Root, change.Path))
+		if change.Reason != "" {
+			line += ": " + change.Reason
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Printf("Итого: %d create, %d modify, %d merge, %d conflict, %d delete, %d orphan, %d unchanged\n",
+		counts[domain.FileCreated], counts[domain.FileModified], counts[domain.FileMerged], counts[domain.FileConflict],
+		counts[domain.FileDeleted], counts[domain.FileOrphaned], counts[domain.FileUnchanged])
+	return nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
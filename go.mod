@@ -4,19 +4,22 @@ go 1.24
 
 require (
 	github.com/Masterminds/sprig/v3 v3.3.0
+	github.com/gin-gonic/gin v1.9.1
+	github.com/golang-migrate/migrate/v4 v4.17.0
 	github.com/iancoleman/strcase v0.3.0
+	github.com/lib/pq v1.10.9
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2
 	github.com/spf13/cobra v1.8.0
-	google.golang.org/grpc v1.62.1
-	google.golang.org/protobuf v1.33.0
-	github.com/gin-gonic/gin v1.9.1
-	github.com/swaggo/gin-swagger v1.6.0
+	github.com/stretchr/testify v1.8.4
 	github.com/swaggo/files v1.0.1
+	github.com/swaggo/gin-swagger v1.6.0
 	github.com/swaggo/swag v1.16.3
-	github.com/lib/pq v1.10.9
-	go.mongodb.org/mongo-driver v1.13.1
-	github.com/golang-migrate/migrate/v4 v4.17.0
-	github.com/stretchr/testify v1.8.4
 	github.com/testcontainers/testcontainers-go v0.27.0
+	go.mongodb.org/mongo-driver v1.13.1
+	golang.org/x/tools v0.30.0
+	google.golang.org/grpc v1.62.1
+	google.golang.org/protobuf v1.33.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -29,9 +32,10 @@ require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/mitchellh/copystructure v1.2.0 // indirect
 	github.com/mitchellh/reflectwalk v1.0.2 // indirect
-	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/shopspring/decimal v1.4.0 // indirect
 	github.com/spf13/cast v1.7.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/crypto v0.26.0 // indirect
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
 )
@@ -1,17 +1,67 @@
 package domain
 
 type ProjectConfig struct {
-	Name         string   `json:"name"`
-	Module       string   `json:"module"`
-	Entities     []Entity `json:"entities"`
-	Repositories []string `json:"repositories"`
-	Features     Features `json:"features"`
-	Port         int      `json:"port,omitempty"`
+	Name string `json:"name"`
+	// Module — путь Go-модуля проекта, из него без изменений строятся все импорты
+	Module string `json:"module"`
+	// GoVersion — версия Go в директиве go файла go.mod
+	GoVersion string `json:"go_version,omitempty"`
+	// Dependencies переопределяют версии зависимостей go.mod или добавляют новые:
+	// путь модуля — версия
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+	Entities     []Entity          `json:"entities"`
+	Repositories []string          `json:"repositories"`
+	Features     Features          `json:"features"`
+	Port         int               `json:"port,omitempty"`
+	Templates    string            `json:"templates,omitempty"`
+	Types        []TypeSpec        `json:"types,omitempty"`
+	// ValueObjects — объекты-значения, которые встраиваются в сущности как поля
+	ValueObjects []ValueObject `json:"value_objects,omitempty"`
 }
 
 type Entity struct {
-	Name   string  `json:"name"`
-	Fields []Field `json:"fields"`
+	Name string `json:"name"`
+	// Имена во множественном числе: Plural — в Go и proto (ListCategories),
+	// Table, Collection и Route — таблица, коллекция и сегмент пути REST.
+	// Незаданные выводятся из имени сущности при разрешении конфигурации
+	Plural     string     `json:"plural,omitempty"`
+	Table      string     `json:"table,omitempty"`
+	Collection string     `json:"collection,omitempty"`
+	Route      string     `json:"route,omitempty"`
+	ID         *IDConfig  `json:"id,omitempty"`
+	Fields     []Field    `json:"fields"`
+	Relations  []Relation `json:"relations,omitempty"`
+	// Pagination — режим страниц списка: offset (по умолчанию) или cursor
+	Pagination string `json:"pagination,omitempty"`
+	// OptimisticLocking добавляет поле Version: Update и Patch записывают
+	// сущность, только если ее версия не изменилась с момента чтения
+	OptimisticLocking bool `json:"optimistic_locking,omitempty"`
+}
+
+// Режимы страниц списка: смещение с общим числом или курсор по времени
+// создания и ключу, который не замедляется на глубоких страницах
+const (
+	PaginationOffset = "offset"
+	PaginationCursor = "cursor"
+)
+
+// Стратегии первичного ключа
+const (
+	IDUUID      = "uuid"
+	IDUUIDv7    = "uuid7"
+	IDULID      = "ulid"
+	IDSerial    = "serial"
+	IDBigSerial = "bigserial"
+	IDSnowflake = "snowflake"
+	IDNatural   = "natural"
+)
+
+type IDConfig struct {
+	Strategy string `json:"strategy,omitempty"`
+	// Field — поле естественного ключа для стратегии natural
+	Field string `json:"field,omitempty"`
+	// Key — поле первичного ключа после разрешения конфигурации
+	Key Field `json:"-"`
 }
 
 type Field struct {
@@ -20,6 +70,58 @@ type Field struct {
 	Tags     []string `json:"tags,omitempty"`
 	Required bool     `json:"required,omitempty"`
 	Unique   bool     `json:"unique,omitempty"`
+	// Nullable разрешает NULL: поле становится указателем, колонка — NULL
+	Nullable bool `json:"nullable,omitempty"`
+	// Values — значения поля типа enum
+	Values []string `json:"values,omitempty"`
+
+	// Правила проверки значения: min/max — для чисел, остальные — для строк
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	MinLength *int     `json:"min_length,omitempty"`
+	MaxLength *int     `json:"max_length,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Email     bool     `json:"email,omitempty"`
+	URL       bool     `json:"url,omitempty"`
+	OneOf     []string `json:"one_of,omitempty"`
+}
+
+// Способы хранения объекта-значения в Postgres
+const (
+	StorageColumns = "columns"
+	StorageJSONB   = "jsonb"
+)
+
+// ValueObject — вложенная структура без собственного ключа. Поле сущности
+// ссылается на объект-значение по имени в type
+type ValueObject struct {
+	Name   string  `json:"name"`
+	Fields []Field `json:"fields"`
+	// Storage — колонки с префиксом имени поля (по умолчанию) или одна колонка JSONB
+	Storage string `json:"storage,omitempty"`
+}
+
+// Типы связей между сущностями
+const (
+	RelationBelongsTo  = "belongs_to"
+	RelationHasMany    = "has_many"
+	RelationManyToMany = "many_to_many"
+)
+
+type Relation struct {
+	Type       string `json:"type"`
+	Entity     string `json:"entity"`
+	ForeignKey string `json:"foreign_key,omitempty"`
+	JoinTable  string `json:"join_table,omitempty"`
+	OnDelete   string `json:"on_delete,omitempty"`
+	// Key и Strategy — первичный ключ связанной сущности и способ его
+	// получения после разрешения конфигурации
+	Key      Field  `json:"-"`
+	Strategy string `json:"-"`
+	// Plural, Table и Route — имена связанной сущности после разрешения конфигурации
+	Plural string `json:"-"`
+	Table  string `json:"-"`
+	Route  string `json:"-"`
 }
 
 type Features struct {
@@ -0,0 +1,29 @@
+package domain
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic описывает проблему в конфигурации проекта
+type Diagnostic struct {
+	Path       string   `json:"path"`
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+	Suggestion string   `json:"suggestion,omitempty"`
+}
+
+// BuildError описывает ошибку компиляции сгенерированного проекта вместе
+// с шаблоном и сущностью, из которых получен файл
+type BuildError struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+	Template string `json:"template,omitempty"`
+	Entity   string `json:"entity,omitempty"`
+	// Source — строка файла, на которую указывает ошибка
+	Source string `json:"source,omitempty"`
+}
@@ -0,0 +1,71 @@
+package domain
+
+// FileSet — результат рендеринга проекта до записи на диск
+type FileSet struct {
+	Root       string
+	ConfigHash string
+	Dirs       []string
+	Files      []*GeneratedFile
+	// LastMigration — наибольший выданный номер миграции после рендеринга
+	LastMigration int
+}
+
+type GeneratedFile struct {
+	Path     string
+	Template string
+	// Entity — сущность, для которой отрендерен файл; пусто у общих файлов
+	Entity  string
+	Content []byte
+}
+
+func NewFileSet(root string) *FileSet {
+	return &FileSet{Root: root}
+}
+
+// Add добавляет файл, заменяя ранее добавленный файл с тем же путем
+func (fs *FileSet) Add(path, templateName string, content []byte) {
+	for _, file := range fs.Files {
+		if file.Path == path {
+			file.Template = templateName
+			file.Content = content
+			return
+		}
+	}
+	fs.Files = append(fs.Files, &GeneratedFile{Path: path, Template: templateName, Content: content})
+}
+
+// Get возвращает файл по относительному пути
+func (fs *FileSet) Get(path string) (*GeneratedFile, bool) {
+	for _, file := range fs.Files {
+		if file.Path == path {
+			return file, true
+		}
+	}
+	return nil, false
+}
+
+// Статусы изменения файла относительно текущего состояния на диске
+const (
+	FileCreated   = "create"
+	FileModified  = "modify"
+	FileMerged    = "merge"
+	FileConflict  = "conflict"
+	FileUnchanged = "unchanged"
+	// Файл больше не генерируется и не изменялся пользователем
+	FileDeleted = "delete"
+	// Файл больше не генерируется, но содержит пользовательские правки
+	FileOrphaned = "orphan"
+)
+
+type FileChange struct {
+	Path      string
+	Template  string
+	Status    string
+	Diff      string
+	Reason    string
+	Conflicts int
+	// Content — итоговое содержимое файла с учетом пользовательских правок
+	Content []byte
+	// Rendered — содержимое, полученное из шаблона
+	Rendered []byte
+}
@@ -0,0 +1,37 @@
+package domain
+
+// Lock — содержимое файла .nibelungo.lock, описывающего последнюю генерацию
+type Lock struct {
+	GeneratorVersion string       `json:"generator_version"`
+	ConfigHash       string       `json:"config_hash"`
+	Files            []LockedFile `json:"files"`
+	// LastMigration — наибольший номер миграции, выданный генератором; номера
+	// удаленных миграций повторно не выдаются
+	LastMigration int `json:"last_migration,omitempty"`
+}
+
+type LockedFile struct {
+	Path     string `json:"path"`
+	Template string `json:"template"`
+	Hash     string `json:"hash"`
+}
+
+// Состояние сгенерированного файла на диске относительно lock-файла
+const (
+	FilePristine = "pristine"
+	FileEdited   = "modified"
+	FileMissing  = "missing"
+)
+
+type FileStatus struct {
+	Path     string `json:"path"`
+	Template string `json:"template"`
+	State    string `json:"state"`
+}
+
+type StatusReport struct {
+	Locked           bool         `json:"locked"`
+	GeneratorVersion string       `json:"generator_version,omitempty"`
+	ConfigChanged    bool         `json:"config_changed"`
+	Files            []FileStatus `json:"files"`
+}
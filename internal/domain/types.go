@@ -0,0 +1,44 @@
+package domain
+
+// TypeSpec описывает логический тип поля и его представление во всех слоях
+// сгенерированного проекта. Форматные строки ToProto, FromProto, SQLWrap и
+// Parse получают Go-выражение через %s; ProtoGoImports и ParseImports —
+// импорты, которые нужны коду преобразования. Типы с Zero могут быть
+// первичным ключом, Parse разбирает ключ из параметра пути или значение
+// фильтра из строки запроса и возвращает значение и ошибку. Filter —
+// операторы, которыми список фильтруется по полям типа; поля типов без них
+// не участвуют в фильтрах и сортировке
+type TypeSpec struct {
+	Name           string   `json:"name"`
+	GoType         string   `json:"go_type"`
+	Imports        []string `json:"imports,omitempty"`
+	Postgres       string   `json:"postgres"`
+	Mongo          string   `json:"mongo,omitempty"`
+	Proto          string   `json:"proto,omitempty"`
+	ProtoImport    string   `json:"proto_import,omitempty"`
+	ProtoGoImports []string `json:"proto_go_imports,omitempty"`
+	ToProto        string   `json:"to_proto,omitempty"`
+	FromProto      string   `json:"from_proto,omitempty"`
+	// ToProtoError и FromProtoError сообщают, что преобразование возвращает
+	// значение и ошибку, как Parse
+	ToProtoError   bool     `json:"to_proto_error,omitempty"`
+	FromProtoError bool     `json:"from_proto_error,omitempty"`
+	OpenAPI        string   `json:"openapi,omitempty"`
+	OpenAPIFormat  string   `json:"openapi_format,omitempty"`
+	SQLWrap        string   `json:"sql_wrap,omitempty"`
+	SQLImports     []string `json:"sql_imports,omitempty"`
+	TestValue      string   `json:"test_value,omitempty"`
+	Zero           string   `json:"zero,omitempty"`
+	Parse          string   `json:"parse,omitempty"`
+	ParseImports   []string `json:"parse_imports,omitempty"`
+	Filter         []string `json:"filter,omitempty"`
+	// Values — значения перечисления, если тип создан для enum-поля
+	Values []string `json:"-"`
+	// Fields — поля объекта-значения; Flatten раскладывает их в Postgres
+	// по отдельным колонкам
+	Fields  []Field `json:"-"`
+	Flatten bool    `json:"-"`
+}
+
+// EnumType — тип поля, значения которого перечислены в Field.Values
+const EnumType = "enum"
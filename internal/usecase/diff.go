@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// unifiedDiff строит unified diff между текущим содержимым файла и новым.
+// Для создаваемого файла исходная сторона — /dev/null, для удаляемого — итоговая
+func unifiedDiff(path string, current, rendered []byte, status string) (string, error) {
+	fromFile, toFile := "a/"+path, "b/"+path
+	switch status {
+	case domain.FileCreated:
+		fromFile = "/dev/null"
+	case domain.FileDeleted:
+		toFile = "/dev/null"
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(current)),
+		B:        splitLines(string(rendered)),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		rendered string
+		status   string
+		want     string
+	}{
+		{
+			name:     "unchanged",
+			current:  "a\nb\n",
+			rendered: "a\nb\n",
+			want:     "",
+		},
+		{
+			name:     "modified",
+			current:  "a\nb\nc\n",
+			rendered: "a\nB\nc\n",
+			status:   domain.FileModified,
+			want:     "--- a/f.go\n+++ b/f.go\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "created",
+			rendered: "a\n",
+			status:   domain.FileCreated,
+			want:     "--- /dev/null\n+++ b/f.go\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:    "deleted",
+			current: "a\nb\n",
+			status:  domain.FileDeleted,
+			want:    "--- a/f.go\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unifiedDiff("f.go", []byte(tt.current), []byte(tt.rendered), tt.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("unifiedDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+	"github.com/iancoleman/strcase"
+)
+
+// enumValue — значение перечисления в Go, Postgres и proto
+type enumValue struct {
+	// Const — имя Go-константы значения
+	Const string
+	Value string
+	// Proto — имя значения proto enum
+	Proto string
+}
+
+// enumType описывает Go-тип, тип Postgres и proto enum одного enum-поля
+type enumType struct {
+	Name     string
+	Postgres string
+	// Unspecified — нулевое значение proto enum
+	Unspecified string
+	Values      []enumValue
+}
+
+// enumName возвращает имя типа перечисления поля: сущность и имя поля
+func enumName(entity string, f domain.Field) string {
+	return strcase.ToCamel(entity) + f.Name
+}
+
+// enumSpec описывает представления типа перечисления во всех слоях
+func enumSpec(entity string, f domain.Field) domain.TypeSpec {
+	name := enumName(entity, f)
+	convert := strcase.ToLowerCamel(name)
+	return domain.TypeSpec{
+		Name:      name,
+		GoType:    name,
+		Postgres:  strcase.ToSnake(name),
+		Mongo:     "string",
+		Proto:     name,
+		ToProto:   convert + "ToProto(%s)",
+		FromProto: convert + "FromProto(%s)",
+		OpenAPI:   "string",
+		TestValue: "domain." + name + strcase.ToCamel(f.Values[0]),
+		Zero:      `""`,
+		Filter:    equalityFilter,
+		Values:    f.Values,
+	}
+}
+
+// resolveEnums заменяет тип enum-полей на тип перечисления и возвращает
+// описания этих типов для реестра
+func resolveEnums(config *domain.ProjectConfig) []domain.TypeSpec {
+	var specs []domain.TypeSpec
+	for i := range config.Entities {
+		entity := &config.Entities[i]
+		for j := range entity.Fields {
+			field := &entity.Fields[j]
+			if field.Type != domain.EnumType {
+				continue
+			}
+			spec := enumSpec(entity.Name, *field)
+			field.Type = spec.Name
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// enumTypes возвращает перечисления, объявленные полями сущности
+func (r *typeRegistry) enumTypes(fields []domain.Field) ([]enumType, error) {
+	var enums []enumType
+	for _, f := range fields {
+		spec, err := r.lookup(f.Type)
+		if err != nil {
+			return nil, err
+		}
+		if len(spec.Values) == 0 {
+			continue
+		}
+		prefix := strcase.ToScreamingSnake(spec.Name) + "_"
+		enum := enumType{Name: spec.Name, Postgres: spec.Postgres, Unspecified: prefix + "UNSPECIFIED"}
+		for _, value := range spec.Values {
+			enum.Values = append(enum.Values, enumValue{
+				Const: spec.Name + strcase.ToCamel(value),
+				Value: value,
+				Proto: prefix + strcase.ToScreamingSnake(value),
+			})
+		}
+		enums = append(enums, enum)
+	}
+	return enums, nil
+}
+
+// checkEnum проверяет значения enum-поля: из каждого получается имя
+// Go-константы и значения proto, и эти имена не совпадают
+func checkEnum(report func(domain.Severity, string, string, string), path string, f domain.Field) {
+	if len(f.Values) == 0 {
+		report(domain.SeverityError, path+".values", fmt.Sprintf("enum field %q has no values", f.Name), `list the allowed values in "values"`)
+		return
+	}
+	consts := make(map[string]string, len(f.Values))
+	for i, value := range f.Values {
+		valuePath := fmt.Sprintf("%s.values[%d]", path, i)
+		camel := strcase.ToCamel(value)
+		switch {
+		case strings.TrimSpace(value) == "":
+			report(domain.SeverityError, valuePath, fmt.Sprintf("enum field %q has an empty value", f.Name), "")
+		case camel == "" || !isIdentifierTail(camel):
+			report(domain.SeverityError, valuePath, fmt.Sprintf("enum value %q of field %q cannot be turned into a Go constant name", value, f.Name), "use letters, digits, spaces, - and _")
+		case strings.EqualFold(strcase.ToScreamingSnake(value), "UNSPECIFIED"):
+			report(domain.SeverityError, valuePath, fmt.Sprintf("enum value %q of field %q clashes with the proto zero value", value, f.Name), "")
+		default:
+			if other, ok := consts[camel]; ok {
+				report(domain.SeverityError, valuePath, fmt.Sprintf("enum value %q of field %q clashes with value %q", value, f.Name, other), "enum values must be unique ignoring case and separators")
+				continue
+			}
+			consts[camel] = value
+		}
+	}
+}
+
+// isIdentifierTail сообщает, что строку можно дописать к Go-идентификатору
+func isIdentifierTail(s string) bool {
+	for _, r := range s {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,520 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateError возвращается, если отрендеренный файл не удалось
+// отформатировать: шаблон породил синтаксически неверный код
+type TemplateError struct {
+	Errors []domain.BuildError
+}
+
+func (e *TemplateError) Error() string {
+	lines := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		line := FormatBuildError(err)
+		if err.Source != "" {
+			line += "\n      " + err.Source
+		}
+		lines = append(lines, line)
+	}
+	return "template produced invalid code:\n  " + strings.Join(lines, "\n  ")
+}
+
+// lineError — ошибка форматирования с позицией в отрендеренном файле
+type lineError struct {
+	line, column int
+	message      string
+}
+
+func (e *lineError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.line, e.column, e.message)
+}
+
+// Пакеты стандартной библиотеки, которые formatGo добавляет в импорты по
+// имени. Имена нескольких пакетов (rand, template) сюда не входят
+var stdlibPackages = map[string]string{
+	"bufio": "bufio", "bytes": "bytes", "context": "context", "driver": "database/sql/driver",
+	"errors": "errors", "filepath": "path/filepath", "fmt": "fmt", "hex": "encoding/hex",
+	"http": "net/http", "httptest": "net/http/httptest", "io": "io", "json": "encoding/json",
+	"log": "log", "mail": "net/mail", "math": "math", "net": "net", "os": "os", "path": "path",
+	"reflect": "reflect", "regexp": "regexp", "signal": "os/signal", "slices": "slices",
+	"sort": "sort", "sql": "database/sql", "strconv": "strconv", "strings": "strings",
+	"sync": "sync", "syscall": "syscall", "testing": "testing", "time": "time", "url": "net/url",
+	"utf8": "unicode/utf8",
+}
+
+var goPackagePattern = regexp.MustCompile(`(?m)^option go_package = "([^";]+)(?:;(\w+))?";`)
+
+// formatFiles нормализует отрендеренные файлы и возвращает ошибки тех, что
+// не удалось разобрать; такие файлы остаются как есть. Импорты пакетов
+// модуля выносятся в отдельную группу. Ошибка — сбой самого форматирования,
+// а не результата шаблона
+func formatFiles(files *domain.FileSet, module string) ([]domain.BuildError, error) {
+	packages := importablePackages(files, module)
+	// Нормализаторы по расширению
+	formatters := map[string]func(src []byte) ([]byte, error){
+		".go":    func(src []byte) ([]byte, error) { return formatGo(src, module, packages) },
+		".proto": formatProto,
+		".sql":   formatSQL,
+		".json":  formatJSON,
+		".yaml":  formatYAML,
+		".yml":   formatYAML,
+	}
+	var errs []domain.BuildError
+	for _, file := range files.Files {
+		format, ok := formatters[filepath.Ext(file.Path)]
+		if !ok {
+			continue
+		}
+		formatted, err := format(file.Content)
+		if err == nil {
+			file.Content = formatted
+			continue
+		}
+		switch err := err.(type) {
+		case scanner.ErrorList:
+			for _, e := range err {
+				errs = append(errs, sourceError(file, e.Pos.Line, e.Pos.Column, e.Msg))
+			}
+		case *lineError:
+			errs = append(errs, sourceError(file, err.line, err.column, err.message))
+		default:
+			return nil, fmt.Errorf("failed to format %s: %w", file.Path, err)
+		}
+	}
+	return errs, nil
+}
+
+// sourceError дополняет ошибку строкой файла, на которую она указывает
+func sourceError(file *domain.GeneratedFile, line, column int, message string) domain.BuildError {
+	e := buildError(file, line, column, message)
+	if lines := strings.Split(string(file.Content), "\n"); line > 0 && line <= len(lines) {
+		e.Source = strings.TrimSpace(lines[line-1])
+	}
+	return e
+}
+
+// importablePackages возвращает пути пакетов по именам: стандартная
+// библиотека, пакеты проекта из отрендеренных Go-файлов и Go-пакеты из
+// option go_package proto-файлов. Имя, под которым известно несколько
+// пакетов, получает пустой путь и не импортируется
+func importablePackages(files *domain.FileSet, module string) map[string]string {
+	packages := make(map[string]string, len(stdlibPackages))
+	for name, path := range stdlibPackages {
+		packages[name] = path
+	}
+	add := func(name, importPath string) {
+		if known, ok := packages[name]; ok && known != importPath {
+			importPath = ""
+		}
+		packages[name] = importPath
+	}
+	seen := make(map[string]bool)
+	for _, file := range files.Files {
+		switch filepath.Ext(file.Path) {
+		case ".go":
+			dir := path.Dir(filepath.ToSlash(file.Path))
+			if seen[dir] || strings.HasSuffix(file.Path, "_test.go") {
+				continue
+			}
+			seen[dir] = true
+			f, err := parser.ParseFile(token.NewFileSet(), "", file.Content, parser.PackageClauseOnly)
+			if err != nil || f.Name.Name == "main" {
+				continue
+			}
+			importPath := module
+			if dir != "." {
+				importPath += "/" + dir
+			}
+			add(f.Name.Name, importPath)
+		case ".proto":
+			if m := goPackagePattern.FindSubmatch(file.Content); m != nil {
+				name := string(m[2])
+				if name == "" {
+					name = path.Base(string(m[1]))
+				}
+				add(name, string(m[1]))
+			}
+		}
+	}
+	return packages
+}
+
+// formatGo форматирует Go-файл как gofmt и приводит импорты к виду goimports:
+// удаляет неиспользуемые, добавляет недостающие из packages и группирует
+// их — стандартная библиотека, сторонние пакеты, пакеты модуля. Тулчейн Go
+// не нужен, поэтому сторонние пакеты перечисляет шаблон
+func formatGo(src []byte, module string, packages map[string]string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if collapsed := collapseBlankLines(fset, file, src); len(collapsed) != len(src) {
+		src = collapsed
+		fset = token.NewFileSet()
+		if file, err = parser.ParseFile(fset, "", src, parser.ParseComments); err != nil {
+			return nil, err
+		}
+	}
+	src = rewriteImports(fset, file, src, module, packages)
+	return format.Source(src)
+}
+
+// collapseBlankLines удаляет пустые строки между элементами составных
+// литералов и списков полей, которые оставляют {{range}} шаблонов. Пустые
+// строки внутри самих элементов, например в телах функций, сохраняются
+func collapseBlankLines(fset *token.FileSet, file *ast.File, src []byte) []byte {
+	tf := fset.File(file.Pos())
+	gaps := make(map[int]bool)
+	collect := func(open token.Pos, items []ast.Node, close token.Pos) {
+		if !open.IsValid() || !close.IsValid() {
+			return
+		}
+		prev := open
+		for _, item := range append(items, nil) {
+			next := close
+			if item != nil {
+				next = item.Pos()
+			}
+			for line := tf.Line(prev) + 1; line < tf.Line(next); line++ {
+				gaps[line] = true
+			}
+			if item != nil {
+				prev = item.End()
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			items := make([]ast.Node, len(n.Elts))
+			for i, elt := range n.Elts {
+				items[i] = elt
+			}
+			collect(n.Lbrace, items, n.Rbrace)
+		case *ast.FieldList:
+			items := make([]ast.Node, len(n.List))
+			for i, field := range n.List {
+				items[i] = field
+			}
+			collect(n.Opening, items, n.Closing)
+		}
+		return true
+	})
+	if len(gaps) == 0 {
+		return src
+	}
+
+	var out bytes.Buffer
+	for i, line := range bytes.SplitAfter(src, []byte("\n")) {
+		if gaps[i+1] && len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		out.Write(line)
+	}
+	return out.Bytes()
+}
+
+// rewriteImports заменяет объявления импортов файла одним блоком с
+// используемыми импортами, сгруппированными по importGroup; пакеты, к
+// которым файл обращается без импорта, добавляются по packages
+func rewriteImports(fset *token.FileSet, file *ast.File, src []byte, module string, packages map[string]string) []byte {
+	used := usedPackages(file)
+	groups := make([][]*ast.ImportSpec, 3)
+	seen := make(map[string]bool, len(file.Imports))
+	imported := make(map[string]bool, len(file.Imports))
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := importName(spec, path)
+		imported[name] = true
+		if name != "_" && name != "." && !used[name] || seen[name+" "+path] {
+			continue
+		}
+		seen[name+" "+path] = true
+		group := importGroup(path, module)
+		groups[group] = append(groups[group], spec)
+	}
+	missing := make([]string, 0, len(used))
+	for name := range used {
+		if !imported[name] && packages[name] != "" && name != file.Name.Name {
+			missing = append(missing, name)
+		}
+	}
+	if len(file.Imports) == 0 && len(missing) == 0 {
+		return src
+	}
+	for _, name := range missing {
+		spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(packages[name])}}
+		if importName(spec, packages[name]) != name {
+			spec.Name = ast.NewIdent(name)
+		}
+		group := importGroup(packages[name], module)
+		groups[group] = append(groups[group], spec)
+	}
+
+	var block bytes.Buffer
+	for _, specs := range groups {
+		if len(specs) == 0 {
+			continue
+		}
+		sort.SliceStable(specs, func(i, j int) bool { return specs[i].Path.Value < specs[j].Path.Value })
+		if block.Len() > 0 {
+			block.WriteString("\n")
+		}
+		for _, spec := range specs {
+			if spec.Doc != nil {
+				for _, c := range spec.Doc.List {
+					block.WriteString("\t" + c.Text + "\n")
+				}
+			}
+			block.WriteString("\t")
+			if spec.Name != nil {
+				block.WriteString(spec.Name.Name + " ")
+			}
+			block.WriteString(spec.Path.Value)
+			if spec.Comment != nil {
+				for _, c := range spec.Comment.List {
+					block.WriteString(" " + c.Text)
+				}
+			}
+			block.WriteString("\n")
+		}
+	}
+
+	// Объявления импортов идут подряд после package, поэтому заменяется
+	// участок от первого до последнего из них; файл без импортов получает
+	// блок после package
+	var first, last *ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			if first == nil {
+				first = gen
+			}
+			last = gen
+		}
+	}
+	start := fset.Position(file.Name.End()).Offset
+	end := start
+	if first != nil {
+		start = fset.Position(first.Pos()).Offset
+		end = fset.Position(last.End()).Offset
+	}
+
+	var out bytes.Buffer
+	out.Write(src[:start])
+	if first == nil {
+		out.WriteString("\n\n")
+	}
+	if block.Len() > 0 {
+		out.WriteString("import (\n")
+		out.Write(block.Bytes())
+		out.WriteString(")")
+	}
+	out.Write(src[end:])
+	return out.Bytes()
+}
+
+// usedPackages возвращает имена, к которым в файле обращаются как к пакетам:
+// x в x.Y, если x не объявлен в файле
+func usedPackages(file *ast.File) map[string]bool {
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	return used
+}
+
+// importName возвращает имя, под которым импорт доступен в файле. Без явного
+// имени оно выводится из пути, как в goimports: последний элемент без
+// суффикса версии /vN, префикса go- и всего после первого символа, не
+// допустимого в идентификаторе. Пакеты, чье имя отличается от пути,
+// шаблон импортирует с явным именем
+func importName(spec *ast.ImportSpec, importPath string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	base := path.Base(importPath)
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil && path.Dir(importPath) != "." {
+			base = path.Base(path.Dir(importPath))
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+// importGroup возвращает группу импорта: 0 — стандартная библиотека,
+// 1 — сторонние пакеты, 2 — пакеты модуля
+func importGroup(importPath, module string) int {
+	switch {
+	case importPath == module || strings.HasPrefix(importPath, module+"/"):
+		return 2
+	case strings.Contains(strings.SplitN(importPath, "/", 2)[0], "."):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// formatProto выравнивает proto-файл по вложенности фигурных скобок
+func formatProto(src []byte) ([]byte, error) {
+	return reindent(src, '{', '}', "  ", "//")
+}
+
+// formatSQL выравнивает миграцию по вложенности круглых скобок
+func formatSQL(src []byte) ([]byte, error) {
+	return reindent(src, '(', ')', "    ", "--")
+}
+
+// reindent расставляет отступы строк по глубине вложенности скобок, удаляет
+// пробелы в концах строк, повторяющиеся пустые строки и пустые строки сразу
+// после открывающей и перед закрывающей скобкой. Скобки внутри строк и
+// комментариев не учитываются
+func reindent(src []byte, open, close byte, indent, comment string) ([]byte, error) {
+	var out []string
+	depth, last := 0, 0
+	for n, raw := range strings.Split(string(src), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			if len(out) > 0 && out[len(out)-1] != "" && !strings.HasSuffix(out[len(out)-1], string(open)) {
+				out = append(out, "")
+			}
+			continue
+		}
+
+		last = n + 1
+		// Столбцы ошибок считаются по исходной строке, с отступом
+		offset := strings.Index(raw, line)
+		level := depth
+		if line[0] == close {
+			level--
+			if len(out) > 0 && out[len(out)-1] == "" {
+				out = out[:len(out)-1]
+			}
+		}
+		var quote byte
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case quote != 0:
+				if c == '\\' && quote == '"' {
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case strings.HasPrefix(line[i:], comment):
+				i = len(line)
+			case c == open:
+				depth++
+			case c == close:
+				depth--
+			}
+			if depth < 0 {
+				return nil, &lineError{line: n + 1, column: offset + i + 1, message: fmt.Sprintf("unexpected %q", close)}
+			}
+		}
+		out = append(out, strings.Repeat(indent, max(level, 0))+line)
+	}
+	if depth > 0 {
+		return nil, &lineError{line: last, column: 1, message: fmt.Sprintf("missing %q", close)}
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return []byte(strings.Join(out, "\n") + "\n"), nil
+}
+
+// formatJSON выравнивает JSON с отступом в четыре пробела
+func formatJSON(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, src, "", "    "); err != nil {
+		if syntax, ok := err.(*json.SyntaxError); ok {
+			// Offset — число прочитанных байтов, включая ошибочный
+			line, column := offsetPosition(src, max(int(syntax.Offset)-1, 0))
+			return nil, &lineError{line: line, column: column, message: syntax.Error()}
+		}
+		return nil, &lineError{message: err.Error()}
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// offsetPosition переводит смещение байта в номер строки и столбца
+func offsetPosition(src []byte, offset int) (line, column int) {
+	offset = min(offset, len(src))
+	before := src[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = offset - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+var yamlLinePattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// formatYAML перекодирует YAML с отступом в два пробела, сохраняя порядок
+// ключей, стиль строк и комментарии; секции верхнего уровня разделяются
+// пустой строкой
+func formatYAML(src []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, &lineError{line: line, column: 1, message: m[2]}
+		}
+		return nil, &lineError{message: err.Error()}
+	}
+	if doc.Kind == 0 {
+		return src, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if i > 0 && line != "" && line[0] != ' ' && line[0] != '-' && line[0] != '#' && !strings.HasPrefix(lines[i-1], "#") {
+			out = append(out, "")
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n") + "\n"), nil
+}
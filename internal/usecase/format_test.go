@@ -0,0 +1,336 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+func TestFormatGo(t *testing.T) {
+	const module = "example.com/shop"
+	packages := map[string]string{
+		"fmt":    "fmt",
+		"time":   "time",
+		"domain": module + "/internal/domain",
+		"grpc":   module + "/pkg/grpc/controller",
+		"user":   module + "/pkg/proto/user",
+	}
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "imports are grouped and sorted",
+			src: `package a
+import (
+"example.com/shop/internal/domain"
+"github.com/gin-gonic/gin"
+"context"
+"fmt"
+)
+func f(ctx context.Context, c *gin.Context) *domain.User { fmt.Println(); return nil }
+`,
+			want: `package a
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/shop/internal/domain"
+)
+
+func f(ctx context.Context, c *gin.Context) *domain.User { fmt.Println(); return nil }
+`,
+		},
+		{
+			name: "unused imports are dropped",
+			src: `package a
+import (
+"fmt"
+"strings"
+"github.com/google/uuid"
+)
+func f() { fmt.Println() }
+`,
+			want: `package a
+
+import (
+	"fmt"
+)
+
+func f() { fmt.Println() }
+`,
+		},
+		{
+			name: "explicit names are kept",
+			src: `package a
+import (
+swaggerFiles "github.com/swaggo/files"
+ginSwagger "github.com/swaggo/gin-swagger"
+"github.com/go-playground/validator/v10"
+)
+var h = ginSwagger.WrapHandler(swaggerFiles.Handler)
+var v = validator.New()
+`,
+			want: `package a
+
+import (
+	"github.com/go-playground/validator/v10"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+var h = ginSwagger.WrapHandler(swaggerFiles.Handler)
+var v = validator.New()
+`,
+		},
+		{
+			name: "missing standard and project imports are added",
+			src: `package a
+import "github.com/gin-gonic/gin"
+func f(c *gin.Context) (*domain.User, *user.User, error) { return nil, nil, fmt.Errorf("at %v", time.Now()) }
+`,
+			want: `package a
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/shop/internal/domain"
+	"example.com/shop/pkg/proto/user"
+)
+
+func f(c *gin.Context) (*domain.User, *user.User, error) {
+	return nil, nil, fmt.Errorf("at %v", time.Now())
+}
+`,
+		},
+		{
+			name: "file without imports gets a block",
+			src: `package a
+var c = grpc.NewUserGRPCController(nil)
+`,
+			want: `package a
+
+import (
+	grpc "example.com/shop/pkg/grpc/controller"
+)
+
+var c = grpc.NewUserGRPCController(nil)
+`,
+		},
+		{
+			name: "own package and unknown names are not imported",
+			src: `package domain
+func f() { domain.X(); unknown.Y() }
+`,
+			want: `package domain
+
+func f() { domain.X(); unknown.Y() }
+`,
+		},
+		{
+			name: "blank lines between literal elements and fields are removed",
+			src: `package a
+type T struct {
+
+	A int
+
+	// B — комментарий
+	B string
+
+}
+var t = T{
+
+	A: 1,
+
+	B: "b",
+
+}
+var f = map[string]func(){
+	"a": func() {
+		println()
+
+		println()
+	},
+}
+`,
+			want: `package a
+
+type T struct {
+	A int
+	// B — комментарий
+	B string
+}
+
+var t = T{
+	A: 1,
+	B: "b",
+}
+var f = map[string]func(){
+	"a": func() {
+		println()
+
+		println()
+	},
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatGo([]byte(tt.src), module, packages)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("formatGo() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportablePackages(t *testing.T) {
+	files := domain.NewFileSet("shop")
+	files.Add("internal/domain/user.go", "domain", []byte("package domain\n"))
+	files.Add("pkg/grpc/controller/user.go", "grpc_controller", []byte("package grpc\n"))
+	files.Add("cmd/server/main.go", "main", []byte("package main\n"))
+	files.Add("internal/fmt/fmt.go", "custom", []byte("package fmt\n"))
+	files.Add("proto/user.proto", "proto", []byte("syntax = \"proto3\";\n\noption go_package = \"example.com/shop/pkg/proto/user\";\n"))
+
+	packages := importablePackages(files, "example.com/shop")
+	want := map[string]string{
+		"domain":  "example.com/shop/internal/domain",
+		"grpc":    "example.com/shop/pkg/grpc/controller",
+		"user":    "example.com/shop/pkg/proto/user",
+		"strings": "strings",
+		"fmt":     "",
+		"main":    "",
+	}
+	for name, path := range want {
+		if packages[name] != path {
+			t.Errorf("packages[%q] = %q, want %q", name, packages[name], path)
+		}
+	}
+}
+
+func TestReindent(t *testing.T) {
+	tests := []struct {
+		name   string
+		format func([]byte) ([]byte, error)
+		src    string
+		want   string
+		err    string
+	}{
+		{
+			name:   "proto",
+			format: formatProto,
+			src:    "message User {\n\n      string id = 1;  \n\n\n   // {не скобка}\nstring name = 2;\n\n}\n\n",
+			want:   "message User {\n  string id = 1;\n\n  // {не скобка}\n  string name = 2;\n}\n",
+		},
+		{
+			name:   "nested proto",
+			format: formatProto,
+			src:    "service S {\nrpc Get(A) returns (B) {\noption (x) = \"}\";\n}\n}\n",
+			want:   "service S {\n  rpc Get(A) returns (B) {\n    option (x) = \"}\";\n  }\n}\n",
+		},
+		{
+			name:   "sql",
+			format: formatSQL,
+			src:    "CREATE TABLE users (\n\nid UUID PRIMARY KEY,\n  email VARCHAR(255) DEFAULT ')' -- (\n\n);\n",
+			want:   "CREATE TABLE users (\n    id UUID PRIMARY KEY,\n    email VARCHAR(255) DEFAULT ')' -- (\n);\n",
+		},
+		{
+			name:   "unexpected close",
+			format: formatSQL,
+			src:    "SELECT 1;\nSELECT (1));\n",
+			err:    "2:11: unexpected ')'",
+		},
+		{
+			name:   "missing close",
+			format: formatProto,
+			src:    "message User {\nstring id = 1;\n",
+			err:    "2:1: missing '}'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.format([]byte(tt.src))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("reindent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFilesReportsTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    domain.BuildError
+	}{
+		{
+			name:    "go",
+			path:    "internal/domain/user.go",
+			content: "package domain\n\nfunc f() {\n\tx := \n}\n",
+			want:    domain.BuildError{Line: 5, Column: 1, Message: "expected operand, found '}'", Source: "}"},
+		},
+		{
+			name:    "sql",
+			path:    "migrations/postgres/001_create_users.up.sql",
+			content: "CREATE TABLE users (\n    id UUID));\n",
+			want:    domain.BuildError{Line: 2, Column: 13, Message: "unexpected ')'", Source: "id UUID));"},
+		},
+		{
+			name:    "json",
+			path:    "migrations/mongodb/001_create_users.up.json",
+			content: "[\n  {\"create\": \"users\",}\n]\n",
+			want:    domain.BuildError{Line: 2, Column: 22, Message: "invalid character '}' looking for beginning of object key string", Source: `{"create": "users",}`},
+		},
+		{
+			name:    "yaml",
+			path:    "config.yaml",
+			content: "port: 8080\ndatabase:\n  host: a: b\n",
+			want:    domain.BuildError{Line: 3, Column: 1, Message: "mapping values are not allowed in this context", Source: "host: a: b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := domain.NewFileSet("shop")
+			files.Files = append(files.Files, &domain.GeneratedFile{Path: tt.path, Template: "template", Entity: "User", Content: []byte(tt.content)})
+			errs, err := formatFiles(files, "example.com/shop")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(errs) != 1 {
+				t.Fatalf("errors = %+v, want one", errs)
+			}
+			want := tt.want
+			want.File, want.Template, want.Entity = tt.path, "template", "User"
+			if errs[0] != want {
+				t.Errorf("error = %+v, want %+v", errs[0], want)
+			}
+
+			message := (&TemplateError{Errors: errs}).Error()
+			if !strings.Contains(message, FormatBuildError(want)) {
+				t.Errorf("TemplateError = %q, want it to contain %q", message, FormatBuildError(want))
+			}
+		})
+	}
+}
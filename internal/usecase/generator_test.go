@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+// testConfig возвращает минимальную конфигурацию с одной сущностью
+func testConfig(entities ...domain.Entity) *domain.ProjectConfig {
+	if len(entities) == 0 {
+		entities = []domain.Entity{{Name: "User", Fields: []domain.Field{{Name: "Email", Type: "string", Required: true}}}}
+	}
+	return &domain.ProjectConfig{
+		Name:         "shop",
+		Module:       "example.com/shop",
+		Entities:     entities,
+		Repositories: []string{"postgres"},
+		Features:     domain.Features{REST: true, GRPC: true, Migrations: true, Tests: true},
+	}
+}
+
+// renderTestProject рендерит проект в пустом каталоге и возвращает
+// содержимое файлов по путям
+func renderTestProject(t *testing.T, config *domain.ProjectConfig) map[string]string {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	files, err := NewGenerator().Render(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := make(map[string]string, len(files.Files))
+	for _, file := range files.Files {
+		rendered[file.Path] = string(file.Content)
+	}
+	return rendered
+}
+
+// hasLine сообщает, что в content есть строка want; пробелы, которыми gofmt
+// выравнивает поля, не учитываются
+func hasLine(content, want string) bool {
+	want = strings.Join(strings.Fields(want), " ")
+	for _, line := range strings.Split(content, "\n") {
+		if strings.Join(strings.Fields(line), " ") == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRenderIsReproducible(t *testing.T) {
+	first := renderTestProject(t, testConfig())
+	second := renderTestProject(t, testConfig())
+	if len(first) != len(second) {
+		t.Fatalf("rendered %d files, then %d", len(first), len(second))
+	}
+	for path, content := range first {
+		if second[path] != content {
+			t.Errorf("%s differs between renders", path)
+		}
+	}
+
+	port := fmt.Sprintf("port: %d\n", defaultPort("example.com/shop"))
+	if !strings.HasPrefix(first["config.yaml"], port) {
+		t.Errorf("config.yaml does not start with %q:\n%s", port, first["config.yaml"])
+	}
+}
+
+func TestDefaultPort(t *testing.T) {
+	for _, module := range []string{"shop", "example.com/shop", "github.com/acme/billing"} {
+		port := defaultPort(module)
+		if port < 8000 || port > 17999 {
+			t.Errorf("defaultPort(%q) = %d, want 8000-17999", module, port)
+		}
+		if defaultPort(module) != port {
+			t.Errorf("defaultPort(%q) is not stable", module)
+		}
+	}
+}
+
+func TestGRPCConversionErrors(t *testing.T) {
+	config := testConfig(domain.Entity{Name: "Payment", Fields: []domain.Field{
+		{Name: "ID", Type: "uuid"},
+		{Name: "Amount", Type: "decimal"},
+		{Name: "PaidAt", Type: "time", Nullable: true},
+		{Name: "Meta", Type: "json"},
+	}})
+	controller := renderTestProject(t, config)["pkg/grpc/controller/payment.go"]
+	for _, want := range []string{
+		`id, err := uuid.Parse(req.GetId())`,
+		`return nil, invalidField("id", err)`,
+		`if value, err := decimal.NewFromString(req.GetAmount()); err != nil {`,
+		`addFieldError(&errs, "amount", err)`,
+		`if value, err := timeFromProto(req.PaidAt); err != nil {`,
+		`return nil, errorStatus(err, codes.InvalidArgument, "invalid payment")`,
+		`if msg.Meta, err = structpb.NewStruct(entity.Meta); err != nil {`,
+	} {
+		if !strings.Contains(controller, want) {
+			t.Errorf("controller does not contain %q:\n%s", want, controller)
+		}
+	}
+	if strings.Contains(controller, ", _ :=") || strings.Contains(controller, ".AsTime()") {
+		t.Errorf("controller discards conversion errors:\n%s", controller)
+	}
+}
+
+func TestGoModRequiresAreSorted(t *testing.T) {
+	config := testConfig()
+	config.Dependencies = map[string]string{"github.com/lib/pq": "v1.10.9", "github.com/gin-gonic/gin": "v1.10.0"}
+	gomod := renderTestProject(t, config)["go.mod"]
+
+	_, block, _ := strings.Cut(gomod, "require (\n")
+	block, _, _ = strings.Cut(block, ")")
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(block), "\n") {
+		paths = append(paths, strings.Fields(line)[0])
+	}
+	if !sort.StringsAreSorted(paths) {
+		t.Errorf("requires are not sorted: %v", paths)
+	}
+	for _, want := range []string{"\tgithub.com/lib/pq v1.10.9\n", "\tgithub.com/gin-gonic/gin v1.10.0\n"} {
+		if !strings.Contains(gomod, want) {
+			t.Errorf("go.mod does not contain %q:\n%s", want, gomod)
+		}
+	}
+}
+
+func TestMongoConnectorRegistersCodecs(t *testing.T) {
+	tests := []struct {
+		name   string
+		field  domain.Field
+		codecs bool
+	}{
+		{"decimal field", domain.Field{Name: "Price", Type: "decimal"}, true},
+		{"no decimal fields", domain.Field{Name: "Title", Type: "string"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig(domain.Entity{Name: "Product", Fields: []domain.Field{tt.field}})
+			config.Repositories = []string{"mongodb"}
+			files := renderTestProject(t, config)
+
+			_, codecs := files["internal/repository/mongodb/codecs.go"]
+			if codecs != tt.codecs {
+				t.Errorf("codecs.go generated = %v, want %v", codecs, tt.codecs)
+			}
+			connector := files["pkg/database/mongodb.go"]
+			registers := strings.Contains(connector, "opts.SetRegistry(mongodb.Registry())") &&
+				strings.Contains(connector, `"example.com/shop/internal/repository/mongodb"`)
+			if registers != tt.codecs {
+				t.Errorf("connector registers codecs = %v, want %v:\n%s", registers, tt.codecs, connector)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+// Версия Go в go.mod, если в конфигурации не задана go_version
+const defaultGoVersion = "1.24"
+
+var (
+	goVersionPattern     = regexp.MustCompile(`^1\.\d+(\.\d+)?$`)
+	moduleVersionPattern = regexp.MustCompile(`^v\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+incompatible)?$`)
+)
+
+// requirement — строка require в go.mod
+type requirement struct {
+	Path    string
+	Version string
+}
+
+// goMod возвращает содержимое go.mod: версии из dependencies конфигурации
+// заменяют версии по умолчанию, новые зависимости добавляются, а строки
+// require сортируются по пути, как после go mod tidy
+func goMod(config *domain.ProjectConfig, requires []requirement) string {
+	goVersion := config.GoVersion
+	if goVersion == "" {
+		goVersion = defaultGoVersion
+	}
+
+	seen := make(map[string]bool, len(requires))
+	for i, req := range requires {
+		if version, ok := config.Dependencies[req.Path]; ok {
+			requires[i].Version = version
+		}
+		seen[req.Path] = true
+	}
+	for path, version := range config.Dependencies {
+		if !seen[path] {
+			requires = append(requires, requirement{path, version})
+		}
+	}
+	sort.Slice(requires, func(i, j int) bool { return requires[i].Path < requires[j].Path })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "module %s\n\ngo %s\n\nrequire (\n", config.Module, goVersion)
+	for _, req := range requires {
+		fmt.Fprintf(&b, "\t%s %s\n", req.Path, req.Version)
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// checkModulePath проверяет путь модуля по правилам путей импорта Go и
+// возвращает описание первой найденной ошибки
+func checkModulePath(path string) string {
+	switch {
+	case !utf8.ValidString(path):
+		return "is not valid UTF-8"
+	case strings.HasPrefix(path, "/") || strings.HasSuffix(path, "/"):
+		return "must not begin or end with a slash"
+	case strings.Contains(path, "://"):
+		return "must not contain a URL scheme"
+	}
+	for _, elem := range strings.Split(path, "/") {
+		switch {
+		case elem == "":
+			return "must not contain empty path elements"
+		case elem == "." || elem == "..":
+			return fmt.Sprintf("must not contain %q elements", elem)
+		case strings.HasPrefix(elem, ".") || strings.HasSuffix(elem, "."):
+			return fmt.Sprintf("element %q must not begin or end with a dot", elem)
+		case strings.HasPrefix(elem, "-"):
+			return fmt.Sprintf("element %q must not begin with a dash", elem)
+		}
+		for _, r := range elem {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-._~", r)) {
+				return fmt.Sprintf("element %q contains invalid character %q", elem, r)
+			}
+		}
+	}
+	return ""
+}
+
+// checkModule проверяет путь модуля, версию Go и зависимости go.mod
+func checkModule(report func(domain.Severity, string, string, string), config *domain.ProjectConfig) {
+	if config.Module == "" {
+		report(domain.SeverityError, "module", "module path is required", `set "module" to the Go module path, e.g. "github.com/acme/my-service"`)
+	} else if problem := checkModulePath(config.Module); problem != "" {
+		report(domain.SeverityError, "module", fmt.Sprintf("module path %q %s", config.Module, problem), `use a path like "gitlab.company.io/platform/my-service"`)
+	} else if first := strings.SplitN(config.Module, "/", 2)[0]; !strings.Contains(first, ".") {
+		report(domain.SeverityWarning, "module", fmt.Sprintf("module path %q has no domain in the first element and cannot be fetched with go get", config.Module), `use a path like "github.com/acme/my-service" if the module will be imported by other projects`)
+	}
+
+	if config.GoVersion != "" && !goVersionPattern.MatchString(config.GoVersion) {
+		report(domain.SeverityError, "go_version", fmt.Sprintf("invalid Go version %q", config.GoVersion), `use a version like "1.24" or "1.24.1"`)
+	}
+
+	paths := make([]string, 0, len(config.Dependencies))
+	for path := range config.Dependencies {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		depPath := "dependencies." + path
+		if problem := checkModulePath(path); problem != "" {
+			report(domain.SeverityError, depPath, fmt.Sprintf("dependency path %q %s", path, problem), "")
+		}
+		if version := config.Dependencies[path]; !moduleVersionPattern.MatchString(version) {
+			report(domain.SeverityError, depPath, fmt.Sprintf("invalid version %q of dependency %q", version, path), `use a semantic version like "v1.9.1"`)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+	"github.com/iancoleman/strcase"
+)
+
+// Неправильные формы множественного числа английских слов
+var irregularPlurals = map[string]string{
+	"alumnus":    "alumni",
+	"analysis":   "analyses",
+	"axis":       "axes",
+	"basis":      "bases",
+	"calf":       "calves",
+	"child":      "children",
+	"crisis":     "crises",
+	"criterion":  "criteria",
+	"datum":      "data",
+	"diagnosis":  "diagnoses",
+	"echo":       "echoes",
+	"elf":        "elves",
+	"foot":       "feet",
+	"goose":      "geese",
+	"half":       "halves",
+	"hero":       "heroes",
+	"knife":      "knives",
+	"leaf":       "leaves",
+	"life":       "lives",
+	"loaf":       "loaves",
+	"man":        "men",
+	"matrix":     "matrices",
+	"medium":     "media",
+	"mouse":      "mice",
+	"ox":         "oxen",
+	"person":     "people",
+	"phenomenon": "phenomena",
+	"potato":     "potatoes",
+	"quiz":       "quizzes",
+	"self":       "selves",
+	"shelf":      "shelves",
+	"thesis":     "theses",
+	"thief":      "thieves",
+	"tomato":     "tomatoes",
+	"tooth":      "teeth",
+	"vertex":     "vertices",
+	"wife":       "wives",
+	"wolf":       "wolves",
+	"woman":      "women",
+}
+
+// Слова, множественное число которых совпадает с единственным
+var uncountables = map[string]bool{
+	"aircraft": true, "data": true, "deer": true, "equipment": true, "feedback": true,
+	"fish": true, "furniture": true, "information": true, "metadata": true, "money": true,
+	"news": true, "people": true, "rice": true, "series": true, "sheep": true,
+	"software": true, "species": true, "staff": true,
+}
+
+// pluralize возвращает множественное число имени в PascalCase: изменяется
+// только последнее слово (OrderItem — OrderItems, Person — People)
+func pluralize(name string) string {
+	words := strings.Split(strcase.ToSnake(name), "_")
+	last := words[len(words)-1]
+	words[len(words)-1] = pluralizeWord(last)
+	return strcase.ToCamel(strings.Join(words, "_"))
+}
+
+func pluralizeWord(word string) string {
+	if word == "" || uncountables[word] {
+		return word
+	}
+	if plural, ok := irregularPlurals[word]; ok {
+		return plural
+	}
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+// entityNames возвращает имена сущности во множественном числе с учетом
+// переопределений из конфигурации
+func entityNames(entity domain.Entity) (plural, table, collection, route string) {
+	plural = strcase.ToCamel(entity.Plural)
+	if plural == "" {
+		plural = pluralize(entity.Name)
+	}
+	table, collection, route = entity.Table, entity.Collection, entity.Route
+	if table == "" {
+		table = strcase.ToSnake(plural)
+	}
+	if collection == "" {
+		collection = strcase.ToSnake(plural)
+	}
+	if route == "" {
+		route = strcase.ToSnake(plural)
+	}
+	return plural, table, collection, strings.Trim(route, "/")
+}
+
+// resolveNames заполняет имена сущностей во множественном числе
+func resolveNames(config *domain.ProjectConfig) {
+	for i := range config.Entities {
+		entity := &config.Entities[i]
+		entity.Plural, entity.Table, entity.Collection, entity.Route = entityNames(*entity)
+	}
+}
+
+var (
+	tablePattern        = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	routeSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9._~-]+$`)
+)
+
+// Максимальная длина идентификатора Postgres
+const maxTableName = 63
+
+// checkNames проверяет имена таблиц, коллекций и маршрутов сущностей: они
+// должны быть допустимы в своем хранилище и не совпадать между сущностями
+func checkNames(report func(domain.Severity, string, string, string), config *domain.ProjectConfig) {
+	plurals := make(map[string]string, len(config.Entities))
+	tables := make(map[string]string, len(config.Entities))
+	collections := make(map[string]string, len(config.Entities))
+	routes := make(map[string]string, len(config.Entities))
+	for i, entity := range config.Entities {
+		if entity.Name == "" {
+			continue
+		}
+		path := fmt.Sprintf("entities[%d]", i)
+		plural, table, collection, route := entityNames(entity)
+
+		if entity.Plural != "" && (!token.IsIdentifier(plural) || !token.IsExported(plural)) {
+			report(domain.SeverityError, path+".plural", fmt.Sprintf("plural %q of entity %q is not a valid Go identifier", entity.Plural, entity.Name), "use letters and digits, e.g. \"People\"")
+		}
+		if !tablePattern.MatchString(table) || len(table) > maxTableName {
+			report(domain.SeverityError, path+".table", fmt.Sprintf("table name %q of entity %q is not a valid Postgres identifier", table, entity.Name), fmt.Sprintf("use lowercase letters, digits and _, at most %d characters", maxTableName))
+		}
+		if strings.ContainsAny(collection, "$\x00") || strings.HasPrefix(collection, "system.") || collection == "" {
+			report(domain.SeverityError, path+".collection", fmt.Sprintf("collection name %q of entity %q is not a valid MongoDB collection name", collection, entity.Name), `do not use "$" or the "system." prefix`)
+		}
+		for _, segment := range strings.Split(route, "/") {
+			if !routeSegmentPattern.MatchString(segment) {
+				report(domain.SeverityError, path+".route", fmt.Sprintf("route %q of entity %q is not a valid URL path", entity.Route, entity.Name), "use path segments of letters, digits, -, _ and ., e.g. \"admin/users\"")
+				break
+			}
+		}
+
+		names := []struct {
+			seen        map[string]string
+			name, field string
+		}{
+			{plurals, plural, "plural"},
+			{tables, table, "table"},
+			{collections, collection, "collection"},
+			{routes, route, "route"},
+		}
+		for _, n := range names {
+			if other, ok := n.seen[n.name]; ok {
+				if strcase.ToSnake(other) == strcase.ToSnake(entity.Name) {
+					// Совпадение имен сущностей уже отмечено
+					continue
+				}
+				report(domain.SeverityError, path+"."+n.field, fmt.Sprintf("%s %q of entity %q clashes with entity %q", n.field, n.name, entity.Name, other), fmt.Sprintf(`set "%s" on one of the entities`, n.field))
+				continue
+			}
+			n.seen[n.name] = entity.Name
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import "testing"
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		// Правила
+		{"User", "Users"},
+		{"Category", "Categories"},
+		{"Day", "Days"},
+		{"Address", "Addresses"},
+		{"Box", "Boxes"},
+		{"Match", "Matches"},
+		{"Wish", "Wishes"},
+		// Неправильные формы
+		{"Person", "People"},
+		{"Child", "Children"},
+		{"Criterion", "Criteria"},
+		{"Leaf", "Leaves"},
+		{"Quiz", "Quizzes"},
+		// Неизменяемые слова
+		{"Sheep", "Sheep"},
+		{"News", "News"},
+		{"Equipment", "Equipment"},
+		{"Metadata", "Metadata"},
+		// Составные имена: меняется только последнее слово
+		{"OrderItem", "OrderItems"},
+		{"SalesPerson", "SalesPeople"},
+		{"ProductCategory", "ProductCategories"},
+		{"UserInformation", "UserInformation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pluralize(tt.name); got != tt.want {
+				t.Errorf("pluralize(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+var supportedStrategies = []string{
+	domain.IDUUID, domain.IDUUIDv7, domain.IDULID, domain.IDSerial,
+	domain.IDBigSerial, domain.IDSnowflake, domain.IDNatural,
+}
+
+// Допустимые типы поля ID для каждой стратегии; первый — тип по умолчанию
+var strategyTypes = map[string][]string{
+	domain.IDUUID:      {"string", "uuid"},
+	domain.IDUUIDv7:    {"string", "uuid"},
+	domain.IDULID:      {"ulid", "string"},
+	domain.IDSerial:    {"int", "int32", "int64"},
+	domain.IDBigSerial: {"int64"},
+	domain.IDSnowflake: {"int64"},
+}
+
+// Пакеты, которыми usecase генерирует значение ключа
+var strategyImports = map[string]string{
+	domain.IDUUID:   "github.com/google/uuid",
+	domain.IDUUIDv7: "github.com/google/uuid",
+	domain.IDULID:   "github.com/oklog/ulid/v2",
+}
+
+// Модули go.mod проекта, которые нужны стратегиям ключей
+var strategyModules = map[string]requirement{
+	domain.IDULID: {"github.com/oklog/ulid/v2", "v2.1.0"},
+}
+
+// dbGenerated сообщает, что значение ключа назначает база данных
+func dbGenerated(strategy string) bool {
+	return strategy == domain.IDSerial || strategy == domain.IDBigSerial
+}
+
+// entityKey определяет стратегию и поле первичного ключа сущности, не
+// изменяя конфигурацию. Объявленное пользователем поле ID считается ключом,
+// а если стратегия не задана, она выводится из его типа
+func entityKey(entity domain.Entity) (string, domain.Field, error) {
+	var config domain.IDConfig
+	if entity.ID != nil {
+		config = *entity.ID
+	}
+
+	if config.Strategy == domain.IDNatural {
+		if config.Field == "" {
+			return "", domain.Field{}, fmt.Errorf("natural key requires a field name")
+		}
+		field, ok := findField(entity, config.Field)
+		if !ok {
+			return "", domain.Field{}, fmt.Errorf("natural key field %q is not declared", config.Field)
+		}
+		return domain.IDNatural, field, nil
+	}
+
+	declared, ok := findField(entity, "ID")
+	strategy := config.Strategy
+	if strategy == "" {
+		strategy = domain.IDUUID
+		if ok {
+			switch declared.Type {
+			case "int", "int32":
+				strategy = domain.IDSerial
+			case "int64":
+				strategy = domain.IDBigSerial
+			case "ulid":
+				strategy = domain.IDULID
+			}
+		}
+	}
+
+	types, supported := strategyTypes[strategy]
+	if !supported {
+		return "", domain.Field{}, fmt.Errorf("unknown id strategy %q", strategy)
+	}
+	if !ok {
+		return strategy, domain.Field{Name: "ID", Type: types[0], Tags: []string{`json:"id" db:"id"`}}, nil
+	}
+	if !contains(types, declared.Type) {
+		return "", domain.Field{}, fmt.Errorf("field ID of type %q cannot hold %s keys, use one of: %s", declared.Type, strategy, strings.Join(types, ", "))
+	}
+	return strategy, declared, nil
+}
+
+// resolveKeys заполняет ключ каждой сущности и убирает поле ключа из
+// списка полей: шаблоны выводят его отдельно
+func resolveKeys(config *domain.ProjectConfig) error {
+	for i := range config.Entities {
+		entity := &config.Entities[i]
+		strategy, key, err := entityKey(*entity)
+		if err != nil {
+			return fmt.Errorf("entity %s: %w", entity.Name, err)
+		}
+
+		id := &domain.IDConfig{Strategy: strategy, Key: key}
+		if entity.ID != nil {
+			id.Field = entity.ID.Field
+		}
+		entity.ID = id
+
+		fields := entity.Fields[:0:0]
+		for _, field := range entity.Fields {
+			if field.Name != key.Name {
+				fields = append(fields, field)
+			}
+		}
+		entity.Fields = fields
+	}
+	return nil
+}
+
+// keyColumnType возвращает тип колонки Postgres, в которой хранится ключ
+// или ссылка на него; строковые ключи известной длины хранятся в колонках
+// этой длины, а ссылки на SERIAL и BIGSERIAL — в INTEGER и BIGINT
+func (r *typeRegistry) keyColumnType(id domain.IDConfig) (string, error) {
+	switch id.Strategy {
+	case domain.IDSerial:
+		return "INTEGER", nil
+	case domain.IDBigSerial:
+		return "BIGINT", nil
+	}
+	if id.Key.Type == "string" {
+		switch id.Strategy {
+		case domain.IDUUID, domain.IDUUIDv7:
+			return "VARCHAR(36)", nil
+		case domain.IDULID:
+			return "CHAR(26)", nil
+		}
+	}
+	spec, err := r.lookup(id.Key.Type)
+	if err != nil {
+		return "", err
+	}
+	return spec.Postgres, nil
+}
+
+// columnType возвращает тип колонки Postgres для поля сущности. Внешний
+// ключ belongs_to хранится в колонке того же типа, что и ключ, на который
+// он ссылается
+func (r *typeRegistry) columnType(entity domain.Entity, field domain.Field) (string, error) {
+	for _, rel := range entity.Relations {
+		if rel.Type == domain.RelationBelongsTo && rel.ForeignKey == field.Name && rel.Key.Type == field.Type {
+			return r.keyColumnType(domain.IDConfig{Strategy: rel.Strategy, Key: rel.Key})
+		}
+	}
+	spec, err := r.lookup(field.Type)
+	if err != nil {
+		return "", err
+	}
+	return spec.Postgres, nil
+}
+
+// keyFields возвращает ключ сущности и ключи связанных сущностей, которые
+// встречаются в сигнатурах методов и параметрах пути
+func keyFields(entity domain.Entity) []domain.Field {
+	keys := []domain.Field{entity.ID.Key}
+	for _, rel := range entity.Relations {
+		if rel.Type != domain.RelationHasMany {
+			keys = append(keys, rel.Key)
+		}
+	}
+	return keys
+}
+
+// keyImports возвращает импорты типов ключей сущности и пакета, которым
+// usecase генерирует новый ключ, кроме уже подключенных шаблоном
+func (r *typeRegistry) keyImports(entity domain.Entity, exclude ...string) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, path := range exclude {
+		seen[path] = true
+	}
+	var paths []string
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	for _, key := range keyFields(entity) {
+		spec, err := r.lookup(key.Type)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range spec.Imports {
+			add(path)
+		}
+	}
+	if path, ok := strategyImports[entity.ID.Strategy]; ok {
+		add(path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func findField(entity domain.Entity, name string) (domain.Field, bool) {
+	for _, field := range entity.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return domain.Field{}, false
+}
+
+// usesStrategy сообщает, что хотя бы одна сущность использует стратегию ключа
+func usesStrategy(entities []domain.Entity, strategy string) bool {
+	for _, entity := range entities {
+		if entity.ID != nil && entity.ID.Strategy == strategy {
+			return true
+		}
+	}
+	return false
+}
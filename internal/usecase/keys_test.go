@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+func TestKeyStrategies(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     *domain.IDConfig
+		fields []domain.Field
+		// Строки, которые должны быть в файлах сущности, по путям
+		want map[string][]string
+		// returning — репозиторий Postgres получает ключ от базы
+		returning bool
+	}{
+		{
+			name: "uuid by default",
+			want: map[string][]string{
+				"migrations/postgres/001_create_tag.up.sql": {"id VARCHAR(36) PRIMARY KEY,"},
+				"internal/domain/tag.go":                    {"ID string `json:\"id\" db:\"id\" bson:\"id\"`"},
+				"internal/usecase/tag.go":                   {"entity.ID = uuid.NewString()"},
+				"internal/controller/tag.go":                {`id := ctx.Param("id")`},
+				"proto/tag.proto":                           {"string id = 1;"},
+			},
+		},
+		{
+			name: "uuid7",
+			id:   &domain.IDConfig{Strategy: domain.IDUUIDv7},
+			want: map[string][]string{
+				"migrations/postgres/001_create_tag.up.sql": {"id VARCHAR(36) PRIMARY KEY,"},
+				"internal/usecase/tag.go":                   {"id, err := uuid.NewV7()", "entity.ID = id.String()"},
+			},
+		},
+		{
+			name: "ulid",
+			id:   &domain.IDConfig{Strategy: domain.IDULID},
+			want: map[string][]string{
+				"migrations/postgres/001_create_tag.up.sql": {"id CHAR(26) PRIMARY KEY,"},
+				"internal/usecase/tag.go":                   {"entity.ID = ulid.Make().String()"},
+			},
+		},
+		{
+			name: "serial",
+			id:   &domain.IDConfig{Strategy: domain.IDSerial},
+			want: map[string][]string{
+				"migrations/postgres/001_create_tag.up.sql": {"id SERIAL PRIMARY KEY,"},
+				"internal/domain/tag.go":                    {"ID int `json:\"id\" db:\"id\" bson:\"id\"`"},
+				"internal/controller/tag.go":                {`id, err := strconv.Atoi(ctx.Param("id"))`},
+				"internal/repository/postgres/tag.go":       {"RETURNING id"},
+				"proto/tag.proto":                           {"int64 id = 1;"},
+			},
+			returning: true,
+		},
+		{
+			name: "bigserial",
+			id:   &domain.IDConfig{Strategy: domain.IDBigSerial},
+			want: map[string][]string{
+				"migrations/postgres/001_create_tag.up.sql": {"id BIGSERIAL PRIMARY KEY,"},
+				"internal/domain/tag.go":                    {"ID int64 `json:\"id\" db:\"id\" bson:\"id\"`"},
+				"internal/controller/tag.go":                {`id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)`},
+			},
+			returning: true,
+		},
+		{
+			name: "snowflake",
+			id:   &domain.IDConfig{Strategy: domain.IDSnowflake},
+			want: map[string][]string{
+				"migrations/postgres/001_create_tag.up.sql": {"id BIGINT PRIMARY KEY,"},
+				"internal/usecase/tag.go":                   {"entity.ID = idgen.Next()"},
+				"proto/tag.proto":                           {"int64 id = 1;"},
+			},
+		},
+		{
+			name: "natural key",
+			id:   &domain.IDConfig{Strategy: domain.IDNatural, Field: "Code"},
+			fields: []domain.Field{
+				{Name: "Code", Type: "string"},
+				{Name: "Title", Type: "string"},
+			},
+			want: map[string][]string{
+				"migrations/postgres/001_create_tag.up.sql": {"code VARCHAR(255) PRIMARY KEY,"},
+				"internal/usecase/tag.go":                   {`if entity.Code == "" {`},
+				"proto/tag.proto":                           {"string code = 1;"},
+			},
+		},
+		{
+			name:   "declared ID field is the key",
+			fields: []domain.Field{{Name: "ID", Type: "uuid"}, {Name: "Title", Type: "string"}},
+			want: map[string][]string{
+				"migrations/postgres/001_create_tag.up.sql": {"id UUID PRIMARY KEY,"},
+				"internal/domain/tag.go":                    {"ID uuid.UUID `json:\"id\" bson:\"id\" swaggertype:\"primitive,string\" format:\"uuid\"`"},
+				"internal/usecase/tag.go":                   {"entity.ID = uuid.New()"},
+				"internal/controller/tag.go":                {`id, err := uuid.Parse(ctx.Param("id"))`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := tt.fields
+			if fields == nil {
+				fields = []domain.Field{{Name: "Title", Type: "string"}}
+			}
+			files := renderTestProject(t, testConfig(domain.Entity{Name: "Tag", ID: tt.id, Fields: fields}))
+			for path, lines := range tt.want {
+				for _, line := range lines {
+					if !hasLine(files[path], line) {
+						t.Errorf("%s does not contain %q:\n%s", path, line, files[path])
+					}
+				}
+			}
+			if got := strings.Count(files["internal/domain/tag.go"], "`json:\"id\""); (tt.id == nil || tt.id.Strategy != domain.IDNatural) && got != 1 {
+				t.Errorf("domain struct declares the key %d times", got)
+			}
+			if got := strings.Contains(files["internal/repository/postgres/tag.go"], "RETURNING"); got != tt.returning {
+				t.Errorf("repository uses RETURNING = %v, want %v", got, tt.returning)
+			}
+		})
+	}
+}
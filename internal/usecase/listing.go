@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+// Операторы фильтров списка, которые понимают сгенерированные репозитории
+var filterOps = []string{"eq", "ne", "gt", "gte", "lt", "lte", "in", "like"}
+
+var supportedPaginations = []string{domain.PaginationOffset, domain.PaginationCursor}
+
+// listField — поле, по которому фильтруется и сортируется список сущности
+type listField struct {
+	// Name — имя поля в JSON, под ним фильтр доходит до репозитория
+	Name string
+	// Alias — имя поля в proto, если оно отличается от Name
+	Alias  string
+	Column string
+	BSON   string
+	Ops    []string
+	// Parse — выражение, разбирающее строку s в значение поля; пустое, если
+	// строка используется как есть
+	Parse string
+}
+
+// listFields возвращает ключ сущности, ее поля, у типов которых есть
+// операторы фильтров, и время создания и изменения
+func (r *typeRegistry) listFields(entity domain.Entity) ([]listField, error) {
+	var result []listField
+	add := func(name, alias, column string, f domain.Field) error {
+		spec, err := r.lookup(f.Type)
+		if err != nil {
+			return err
+		}
+		if len(spec.Filter) == 0 {
+			return nil
+		}
+		field := listField{Name: name, Column: column, BSON: bsonName(f.Name), Ops: spec.Filter}
+		if alias != name {
+			field.Alias = alias
+		}
+		switch {
+		case len(spec.Values) > 0:
+			field.Parse = "Parse" + spec.Name + "(s)"
+		case spec.Parse != "":
+			field.Parse = fmt.Sprintf(spec.Parse, "s")
+		}
+		result = append(result, field)
+		return nil
+	}
+
+	for _, f := range append([]domain.Field{entity.ID.Key}, entity.Fields...) {
+		if err := add(jsonName(f), protoName(f.Name), columnName(f.Name), f); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range []string{"CreatedAt", "UpdatedAt"} {
+		if err := add(jsonName(domain.Field{Name: name}), protoName(name), columnName(name), domain.Field{Name: name, Type: "time"}); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// listImports возвращает пакеты, которые нужны разбору значений фильтров:
+// время создания и изменения разбирается всегда, перечисления сообщают о
+// неизвестном значении через fmt
+func (r *typeRegistry) listImports(entity domain.Entity) ([]string, error) {
+	seen := map[string]bool{"time": true}
+	for _, f := range append([]domain.Field{entity.ID.Key}, entity.Fields...) {
+		spec, err := r.lookup(f.Type)
+		if err != nil {
+			return nil, err
+		}
+		if len(spec.Filter) == 0 {
+			continue
+		}
+		paths := spec.ParseImports
+		if len(spec.Values) > 0 {
+			paths = []string{"fmt"}
+		}
+		for _, path := range paths {
+			seen[path] = true
+		}
+	}
+	imports := make([]string, 0, len(seen))
+	for path := range seen {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	return imports, nil
+}
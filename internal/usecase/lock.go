@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+// Version — версия генератора, записываемая в lock-файл
+const Version = "0.5.0"
+
+const lockFile = ".nibelungo.lock"
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// hashConfig вычисляет хеш конфигурации с приведенными именами и шаблонов,
+// переопределяющих встроенные. Путь к каталогу шаблонов в хеш не входит:
+// важно только содержимое шаблонов, а не то, откуда они взяты
+func hashConfig(config *domain.ProjectConfig) (string, error) {
+	canonical := *config
+	canonical.Templates = ""
+	data, err := json.Marshal(&canonical)
+	if err != nil {
+		return "", err
+	}
+	if config.Templates == "" {
+		return hashContent(data), nil
+	}
+
+	entries, err := os.ReadDir(config.Templates)
+	if err != nil {
+		return "", fmt.Errorf("failed to read templates directory: %w", err)
+	}
+	// Имя и содержимое каждого шаблона отделяются нулевым байтом, поэтому
+	// разные наборы шаблонов не дают одинаковый поток
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != templateExt {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(config.Templates, entry.Name()))
+		if err != nil {
+			return "", err
+		}
+		data = append(append(append(data, 0), entry.Name()...), 0)
+		data = append(data, content...)
+	}
+	return hashContent(data), nil
+}
+
+// readLock читает lock-файл проекта; если его нет, возвращает nil
+func readLock(root string) (*domain.Lock, error) {
+	data, err := os.ReadFile(filepath.Join(root, lockFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lock domain.Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+func writeLock(root string, lock *domain.Lock) error {
+	sort.Slice(lock.Files, func(i, j int) bool {
+		return lock.Files[i].Path < lock.Files[j].Path
+	})
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(root, lockFile), append(data, '\n'))
+}
+
+// Путь миграции: номер и имя, например migrations/postgres/002_create_tag.up.sql
+var migrationPattern = regexp.MustCompile(`^migrations/(?:postgres|mongodb)/(\d+)_(\w+)\.up\.(?:sql|json)$`)
+
+// migrationVersions возвращает номера миграций из lock-файла по имени
+// (create_user) и наибольший выданный номер
+func migrationVersions(lock *domain.Lock) (map[string]int, int) {
+	versions := make(map[string]int)
+	if lock == nil {
+		return versions, 0
+	}
+	last := lock.LastMigration
+	for _, file := range lock.Files {
+		m := migrationPattern.FindStringSubmatch(filepath.ToSlash(file.Path))
+		if m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		versions[m[2]] = version
+		last = max(last, version)
+	}
+	return versions, last
+}
+
+func lockedFiles(lock *domain.Lock) map[string]domain.LockedFile {
+	files := make(map[string]domain.LockedFile)
+	if lock == nil {
+		return files
+	}
+	for _, file := range lock.Files {
+		files[file.Path] = file
+	}
+	return files
+}
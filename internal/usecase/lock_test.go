@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+func TestMigrationVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		lock     *domain.Lock
+		versions map[string]int
+		last     int
+	}{
+		{
+			name:     "no lock",
+			versions: map[string]int{},
+		},
+		{
+			name: "migrations of both repositories",
+			lock: &domain.Lock{Files: []domain.LockedFile{
+				{Path: "migrations/postgres/001_create_user.up.sql"},
+				{Path: "migrations/postgres/001_create_user.down.sql"},
+				{Path: "migrations/mongodb/002_create_tag.up.json"},
+				{Path: "internal/domain/user.go"},
+			}},
+			versions: map[string]int{"create_user": 1, "create_tag": 2},
+			last:     2,
+		},
+		{
+			name: "numbers of removed migrations are not reused",
+			lock: &domain.Lock{
+				Files:         []domain.LockedFile{{Path: "migrations/postgres/001_create_user.up.sql"}},
+				LastMigration: 3,
+			},
+			versions: map[string]int{"create_user": 1},
+			last:     3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions, last := migrationVersions(tt.lock)
+			if !reflect.DeepEqual(versions, tt.versions) {
+				t.Errorf("versions = %v, want %v", versions, tt.versions)
+			}
+			if last != tt.last {
+				t.Errorf("last = %d, want %d", last, tt.last)
+			}
+		})
+	}
+}
+
+func TestHashConfig(t *testing.T) {
+	base, err := hashConfig(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		change  func(config *domain.ProjectConfig)
+		changed bool
+	}{
+		{"same config", func(config *domain.ProjectConfig) {}, false},
+		{"field added", func(config *domain.ProjectConfig) {
+			config.Entities[0].Fields = append(config.Entities[0].Fields, domain.Field{Name: "Name", Type: "string"})
+		}, true},
+		{"feature disabled", func(config *domain.ProjectConfig) { config.Features.GRPC = false }, true},
+		{"templates directory without overrides", func(config *domain.ProjectConfig) { config.Templates = t.TempDir() }, false},
+		{"template override", func(config *domain.ProjectConfig) {
+			config.Templates = t.TempDir()
+			writeTestFile(t, filepath.Join(config.Templates, "domain"+templateExt), "package domain\n")
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig()
+			tt.change(config)
+			hash, err := hashConfig(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed := hash != base; changed != tt.changed {
+				t.Errorf("hash changed = %v, want %v", changed, tt.changed)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	const path = "internal/domain/user.go"
+	tests := []struct {
+		name          string
+		change        func(t *testing.T, config *domain.ProjectConfig)
+		locked        bool
+		configChanged bool
+		state         string
+	}{
+		{
+			name:   "pristine",
+			change: func(t *testing.T, config *domain.ProjectConfig) {},
+			locked: true,
+			state:  domain.FilePristine,
+		},
+		{
+			name: "edited file",
+			change: func(t *testing.T, config *domain.ProjectConfig) {
+				writeTestFile(t, filepath.Join("shop", path), "package domain\n")
+			},
+			locked: true,
+			state:  domain.FileEdited,
+		},
+		{
+			name: "removed file",
+			change: func(t *testing.T, config *domain.ProjectConfig) {
+				if err := os.Remove(filepath.Join("shop", path)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			locked: true,
+			state:  domain.FileMissing,
+		},
+		{
+			name: "changed config",
+			change: func(t *testing.T, config *domain.ProjectConfig) {
+				config.Entities[0].Fields[0].Unique = true
+			},
+			locked:        true,
+			configChanged: true,
+			state:         domain.FilePristine,
+		},
+		{
+			name: "no lock",
+			change: func(t *testing.T, config *domain.ProjectConfig) {
+				if err := os.Remove(filepath.Join("shop", lockFile)); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			g := NewGenerator()
+			files, err := g.Render(testConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := g.Write(files, false); err != nil {
+				t.Fatal(err)
+			}
+
+			config := testConfig()
+			tt.change(t, config)
+			report, err := g.Status(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Locked != tt.locked {
+				t.Fatalf("locked = %v, want %v", report.Locked, tt.locked)
+			}
+			if !tt.locked {
+				return
+			}
+			if report.GeneratorVersion != Version {
+				t.Errorf("generator version = %q, want %q", report.GeneratorVersion, Version)
+			}
+			if report.ConfigChanged != tt.configChanged {
+				t.Errorf("config changed = %v, want %v", report.ConfigChanged, tt.configChanged)
+			}
+			if len(report.Files) != len(files.Files) {
+				t.Errorf("status lists %d files, rendered %d", len(report.Files), len(files.Files))
+			}
+			for _, file := range report.Files {
+				want := domain.FilePristine
+				if file.Path == path {
+					want = tt.state
+				}
+				if file.State != want {
+					t.Errorf("%s: state = %s, want %s", file.Path, file.State, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrationNumbersAreKept(t *testing.T) {
+	t.Chdir(t.TempDir())
+	g := NewGenerator()
+	user := domain.Entity{Name: "User", Fields: []domain.Field{{Name: "Email", Type: "string"}}}
+	files, err := g.Render(testConfig(user))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Write(files, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Новая сущность перед уже созданной получает следующий номер, а номер
+	// существующей миграции не меняется
+	tag := domain.Entity{Name: "Tag", Fields: []domain.Field{{Name: "Title", Type: "string"}}}
+	files, err = g.Render(testConfig(tag, user))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := make(map[string]bool)
+	for _, file := range files.Files {
+		rendered[file.Path] = true
+	}
+	for _, path := range []string{
+		"migrations/postgres/001_create_user.up.sql",
+		"migrations/postgres/002_create_tag.up.sql",
+	} {
+		if !rendered[path] {
+			t.Errorf("%s is not rendered", path)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Защищенные области: содержимое между маркерами принадлежит пользователю
+// и переносится в заново сгенерированный файл без изменений
+//
+//	// nibelungo:keep begin create
+//	...
+//	// nibelungo:keep end create
+var (
+	keepBeginRe = regexp.MustCompile(`nibelungo:keep begin (\S+)`)
+	keepEndRe   = regexp.MustCompile(`nibelungo:keep end\b`)
+)
+
+const (
+	conflictOurs   = "<<<<<<< current\n"
+	conflictSep    = "=======\n"
+	conflictTheirs = ">>>>>>> generated\n"
+)
+
+// splitLines разбивает текст на строки, сохраняя переводы строк
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// extractRegions возвращает содержимое защищенных областей по их именам
+func extractRegions(lines []string) map[string][]string {
+	regions := make(map[string][]string)
+	name, inside := "", false
+	for _, line := range lines {
+		switch {
+		case !inside && keepBeginRe.MatchString(line):
+			name, inside = keepBeginRe.FindStringSubmatch(line)[1], true
+			regions[name] = nil
+		case inside && keepEndRe.MatchString(line):
+			inside = false
+		case inside:
+			regions[name] = append(regions[name], line)
+		}
+	}
+	return regions
+}
+
+// applyRegions подставляет пользовательское содержимое в одноименные защищенные
+// области и возвращает имена областей, для которых в новом файле нет места
+func applyRegions(lines []string, regions map[string][]string) ([]string, []string) {
+	used := make(map[string]bool, len(regions))
+	result := make([]string, 0, len(lines))
+	inside := false
+	for _, line := range lines {
+		switch {
+		case !inside && keepBeginRe.MatchString(line):
+			result = append(result, line)
+			name := keepBeginRe.FindStringSubmatch(line)[1]
+			if content, ok := regions[name]; ok {
+				result = append(result, content...)
+				used[name] = true
+				inside = true
+			}
+		case inside && keepEndRe.MatchString(line):
+			result = append(result, line)
+			inside = false
+		case inside:
+			// Содержимое области в шаблоне заменено пользовательским
+		default:
+			result = append(result, line)
+		}
+	}
+
+	var lost []string
+	for name, content := range regions {
+		if !used[name] && len(content) > 0 {
+			lost = append(lost, name)
+		}
+	}
+	return result, lost
+}
+
+// merge3 выполняет построчное трехстороннее слияние: base — ранее
+// сгенерированная версия, ours — текущий файл на диске, theirs — новая
+// генерация. Конфликтующие участки помечаются маркерами
+func merge3(base, ours, theirs []string) ([]string, int) {
+	type sync struct{ base0, base1, ours0, ours1, theirs0, theirs1 int }
+
+	oursBlocks := difflib.NewMatcherWithJunk(base, ours, false, nil).GetMatchingBlocks()
+	theirsBlocks := difflib.NewMatcherWithJunk(base, theirs, false, nil).GetMatchingBlocks()
+
+	// Участки base, совпадающие одновременно с обеими сторонами
+	var syncs []sync
+	for i, j := 0, 0; i < len(oursBlocks) && j < len(theirsBlocks); {
+		o, t := oursBlocks[i], theirsBlocks[j]
+		lo, hi := max(o.A, t.A), min(o.A+o.Size, t.A+t.Size)
+		if lo < hi {
+			oursStart, theirsStart := o.B+lo-o.A, t.B+lo-t.A
+			syncs = append(syncs, sync{lo, hi, oursStart, oursStart + hi - lo, theirsStart, theirsStart + hi - lo})
+		}
+		if o.A+o.Size < t.A+t.Size {
+			i++
+		} else {
+			j++
+		}
+	}
+	syncs = append(syncs, sync{len(base), len(base), len(ours), len(ours), len(theirs), len(theirs)})
+
+	var result []string
+	conflicts := 0
+	baseAt, oursAt, theirsAt := 0, 0, 0
+	for _, s := range syncs {
+		baseChunk := base[baseAt:s.base0]
+		oursChunk := ours[oursAt:s.ours0]
+		theirsChunk := theirs[theirsAt:s.theirs0]
+
+		oursChanged := !equalLines(oursChunk, baseChunk)
+		theirsChanged := !equalLines(theirsChunk, baseChunk)
+		switch {
+		case oursChanged && theirsChanged && !equalLines(oursChunk, theirsChunk):
+			conflicts++
+			result = append(result, conflictOurs)
+			result = append(result, terminated(oursChunk)...)
+			result = append(result, conflictSep)
+			result = append(result, terminated(theirsChunk)...)
+			result = append(result, conflictTheirs)
+		case oursChanged:
+			result = append(result, oursChunk...)
+		default:
+			result = append(result, theirsChunk...)
+		}
+
+		result = append(result, base[s.base0:s.base1]...)
+		baseAt, oursAt, theirsAt = s.base1, s.ours1, s.theirs1
+	}
+
+	return result, conflicts
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// terminated гарантирует перевод строки у последней строки участка,
+// чтобы маркеры конфликта начинались с новой строки
+func terminated(lines []string) []string {
+	if len(lines) == 0 || strings.HasSuffix(lines[len(lines)-1], "\n") {
+		return lines
+	}
+	result := append([]string(nil), lines...)
+	result[len(result)-1] += "\n"
+	return result
+}
@@ -0,0 +1,303 @@
+package usecase
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"trailing newline", "a\nb\n", []string{"a\n", "b\n"}},
+		{"no trailing newline", "a\nb", []string{"a\n", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitLines(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitLines(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegions(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		rendered string
+		want     string
+		lost     []string
+	}{
+		{
+			name:     "user code is kept",
+			current:  "a\n// nibelungo:keep begin create\nuser()\n// nibelungo:keep end create\nb\n",
+			rendered: "a2\n// nibelungo:keep begin create\n// nibelungo:keep end create\nb2\n",
+			want:     "a2\n// nibelungo:keep begin create\nuser()\n// nibelungo:keep end create\nb2\n",
+		},
+		{
+			name:     "template placeholder is replaced",
+			current:  "// nibelungo:keep begin x\n// nibelungo:keep end x\n",
+			rendered: "// nibelungo:keep begin x\ntodo()\n// nibelungo:keep end x\n",
+			want:     "// nibelungo:keep begin x\n// nibelungo:keep end x\n",
+		},
+		{
+			name:     "region without user code is not in the new file",
+			current:  "// nibelungo:keep begin old\n// nibelungo:keep end old\n",
+			rendered: "a\n",
+			want:     "a\n",
+		},
+		{
+			name:     "region with user code is not in the new file",
+			current:  "// nibelungo:keep begin old\nuser()\n// nibelungo:keep end old\n",
+			rendered: "a\n",
+			want:     "a\n",
+			lost:     []string{"old"},
+		},
+		{
+			name:     "regions are matched by name",
+			current:  "// nibelungo:keep begin b\nB\n// nibelungo:keep end b\n// nibelungo:keep begin a\nA\n// nibelungo:keep end a\n",
+			rendered: "// nibelungo:keep begin a\n// nibelungo:keep end a\n// nibelungo:keep begin b\n// nibelungo:keep end b\n",
+			want:     "// nibelungo:keep begin a\nA\n// nibelungo:keep end a\n// nibelungo:keep begin b\nB\n// nibelungo:keep end b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regions := extractRegions(splitLines(tt.current))
+			got, lost := applyRegions(splitLines(tt.rendered), regions)
+			if strings.Join(got, "") != tt.want {
+				t.Errorf("applyRegions() = %q, want %q", strings.Join(got, ""), tt.want)
+			}
+			if !reflect.DeepEqual(lost, tt.lost) {
+				t.Errorf("lost = %q, want %q", lost, tt.lost)
+			}
+		})
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "no changes",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "template change only",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "local change only",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\nlocal\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\nlocal\n",
+		},
+		{
+			name:   "changes in different places",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "a\nlocal\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "a\nlocal\nc\nd\nE\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:      "conflicting edit",
+			base:      "a\nb\nc\n",
+			ours:      "a\nlocal\nc\n",
+			theirs:    "a\ngenerated\nc\n",
+			want:      "a\n" + conflictOurs + "local\n" + conflictSep + "generated\n" + conflictTheirs + "c\n",
+			conflicts: 1,
+		},
+		{
+			name:      "conflict on the last line without newline",
+			base:      "a\nb",
+			ours:      "a\nlocal",
+			theirs:    "a\ngenerated",
+			want:      "a\n" + conflictOurs + "local\n" + conflictSep + "generated\n" + conflictTheirs,
+			conflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := merge3(splitLines(tt.base), splitLines(tt.ours), splitLines(tt.theirs))
+			if strings.Join(got, "") != tt.want {
+				t.Errorf("merge3() = %q, want %q", strings.Join(got, ""), tt.want)
+			}
+			if conflicts != tt.conflicts {
+				t.Errorf("conflicts = %d, want %d", conflicts, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestPlanFile(t *testing.T) {
+	const rendered = "a\n// nibelungo:keep begin x\n// nibelungo:keep end x\nb\n"
+	tests := []struct {
+		name    string
+		current string
+		base    string
+		status  string
+		content string
+	}{
+		{
+			name:    "new file",
+			status:  domain.FileCreated,
+			content: rendered,
+		},
+		{
+			name:    "identical file without base",
+			current: rendered,
+			status:  domain.FileUnchanged,
+			content: rendered,
+		},
+		{
+			name:    "edited file without base",
+			current: "a\nlocal\n",
+			status:  domain.FileConflict,
+		},
+		{
+			name:    "user code in a region",
+			current: "a\n// nibelungo:keep begin x\nuser()\n// nibelungo:keep end x\nb\n",
+			base:    rendered,
+			status:  domain.FileUnchanged,
+			content: "a\n// nibelungo:keep begin x\nuser()\n// nibelungo:keep end x\nb\n",
+		},
+		{
+			name:    "template changed",
+			current: "a0\n// nibelungo:keep begin x\n// nibelungo:keep end x\nb\n",
+			base:    "a0\n// nibelungo:keep begin x\n// nibelungo:keep end x\nb\n",
+			status:  domain.FileModified,
+			content: rendered,
+		},
+		{
+			name:    "template and file changed",
+			current: "a0\n// nibelungo:keep begin x\n// nibelungo:keep end x\nb\nlocal\n",
+			base:    "a0\n// nibelungo:keep begin x\n// nibelungo:keep end x\nb\n",
+			status:  domain.FileMerged,
+			content: rendered + "local\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if tt.current != "" {
+				writeTestFile(t, filepath.Join(root, "f.go"), tt.current)
+			}
+			if tt.base != "" {
+				writeTestFile(t, filepath.Join(root, baseDir, "f.go"), tt.base)
+			}
+
+			change, _, err := (&generator{}).planFile(root, &domain.GeneratedFile{Path: "f.go", Content: []byte(rendered)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if change.Status != tt.status {
+				t.Errorf("status = %s (%s), want %s", change.Status, change.Reason, tt.status)
+			}
+			if tt.content != "" && string(change.Content) != tt.content {
+				t.Errorf("content = %q, want %q", change.Content, tt.content)
+			}
+		})
+	}
+}
+
+func TestPlanDeletions(t *testing.T) {
+	const content = "a\n"
+	tests := []struct {
+		name    string
+		path    string
+		current string
+		status  string
+		diff    string
+	}{
+		{
+			name:    "file no longer generated",
+			path:    "internal/domain/tag.go",
+			current: content,
+			status:  domain.FileDeleted,
+			diff:    "--- a/shop/internal/domain/tag.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name:    "file with local changes",
+			path:    "internal/domain/tag.go",
+			current: "a\nlocal\n",
+			status:  domain.FileOrphaned,
+		},
+		{
+			name:    "postgres migration is kept",
+			path:    "migrations/postgres/004_create_tag.up.sql",
+			current: content,
+			status:  domain.FileOrphaned,
+		},
+		{
+			name:    "mongodb migration is kept",
+			path:    "migrations/mongodb/004_create_tag.up.json",
+			current: content,
+			status:  domain.FileOrphaned,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			writeTestFile(t, filepath.Join("shop", tt.path), tt.current)
+			lock := &domain.Lock{
+				Files:         []domain.LockedFile{{Path: tt.path, Hash: hashContent([]byte(content))}},
+				LastMigration: 4,
+			}
+			if err := writeLock("shop", lock); err != nil {
+				t.Fatal(err)
+			}
+
+			changes, err := (&generator{}).Plan(domain.NewFileSet("shop"), true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changes) != 1 {
+				t.Fatalf("changes = %+v, want one", changes)
+			}
+			if changes[0].Status != tt.status {
+				t.Errorf("status = %s (%s), want %s", changes[0].Status, changes[0].Reason, tt.status)
+			}
+			if changes[0].Diff != tt.diff {
+				t.Errorf("diff = %q, want %q", changes[0].Diff, tt.diff)
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
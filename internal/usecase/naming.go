@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"go/token"
+	"reflect"
+	"strings"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+	"github.com/iancoleman/strcase"
+)
+
+// Слоги, которые в Go-именах пишутся заглавными целиком (UserID, AvatarURL)
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "https": true, "id": true, "ip": true,
+	"json": true, "sql": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// Ключевые слова proto, которые нельзя использовать как имя пакета
+var protoKeywords = map[string]bool{
+	"enum": true, "extend": true, "extensions": true, "false": true, "import": true,
+	"map": true, "max": true, "message": true, "oneof": true, "option": true,
+	"optional": true, "package": true, "public": true, "repeated": true, "reserved": true,
+	"returns": true, "rpc": true, "service": true, "stream": true, "syntax": true,
+	"to": true, "true": true, "weak": true,
+}
+
+// Методы сообщений protoc-gen-go: поле с таким именем получает суффикс _
+var protoMethods = map[string]bool{
+	"Descriptor": true, "ExtensionMap": true, "ExtensionRangeArray": true, "Marshal": true,
+	"ProtoMessage": true, "Reset": true, "String": true, "Unmarshal": true,
+}
+
+// Зарезервированные слова Postgres, которые нельзя использовать как имя
+// колонки или таблицы без кавычек
+var sqlReserved = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true,
+	"as": true, "asc": true, "asymmetric": true, "authorization": true, "binary": true,
+	"both": true, "case": true, "cast": true, "check": true, "collate": true, "collation": true,
+	"column": true, "concurrently": true, "constraint": true, "create": true, "cross": true,
+	"current_catalog": true, "current_date": true, "current_role": true, "current_schema": true,
+	"current_time": true, "current_timestamp": true, "current_user": true, "default": true,
+	"deferrable": true, "desc": true, "distinct": true, "do": true, "else": true, "end": true,
+	"except": true, "false": true, "fetch": true, "for": true, "foreign": true, "freeze": true,
+	"from": true, "full": true, "grant": true, "group": true, "having": true, "ilike": true,
+	"in": true, "initially": true, "inner": true, "intersect": true, "into": true, "is": true,
+	"isnull": true, "join": true, "lateral": true, "leading": true, "left": true, "like": true,
+	"limit": true, "localtime": true, "localtimestamp": true, "natural": true, "not": true,
+	"notnull": true, "null": true, "offset": true, "on": true, "only": true, "or": true,
+	"order": true, "outer": true, "overlaps": true, "placing": true, "primary": true,
+	"references": true, "returning": true, "right": true, "select": true, "session_user": true,
+	"similar": true, "some": true, "symmetric": true, "table": true, "tablesample": true,
+	"then": true, "to": true, "trailing": true, "true": true, "union": true, "unique": true,
+	"user": true, "using": true, "variadic": true, "verbose": true, "when": true, "where": true,
+	"window": true, "with": true,
+}
+
+// goName приводит имя из конфигурации к экспортируемому Go-идентификатору:
+// user — User, order_item — OrderItem, user_id — UserID. Имена, которые уже
+// являются экспортируемыми идентификаторами, не меняются
+func goName(name string) string {
+	if token.IsIdentifier(name) && token.IsExported(name) {
+		return name
+	}
+	words := strings.Split(strcase.ToSnake(name), "_")
+	for i, word := range words {
+		if initialisms[word] {
+			words[i] = strings.ToUpper(word)
+		} else {
+			words[i] = strcase.ToCamel(word)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// goVar возвращает имя локальной переменной; ключевые слова Go получают суффикс _
+func goVar(name string) string {
+	v := strcase.ToLowerCamel(name)
+	if token.IsKeyword(v) {
+		return v + "_"
+	}
+	return v
+}
+
+// goPackage возвращает имя Go- и proto-пакета сущности; ключевые слова
+// получают суффикс pb
+func goPackage(name string) string {
+	pkg := strings.ToLower(name)
+	if token.IsKeyword(pkg) || protoKeywords[pkg] {
+		return pkg + "pb"
+	}
+	return pkg
+}
+
+// sqlName заключает в кавычки зарезервированные слова Postgres
+func sqlName(name string) string {
+	if sqlReserved[name] {
+		return `"` + name + `"`
+	}
+	return name
+}
+
+// columnName возвращает имя колонки Postgres для поля
+func columnName(field string) string {
+	return sqlName(strcase.ToSnake(field))
+}
+
+// jsonName возвращает имя поля в JSON: из тега json или имя поля в lowerCamelCase
+func jsonName(f domain.Field) string {
+	for _, tag := range f.Tags {
+		name := strings.SplitN(reflect.StructTag(tag).Get("json"), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return strcase.ToLowerCamel(f.Name)
+}
+
+// bsonName возвращает имя поля в документе MongoDB в snake_case, как имя
+// колонки Postgres; структуры домена получают его в теге bson
+func bsonName(field string) string {
+	return strcase.ToSnake(field)
+}
+
+// protoName возвращает имя поля proto в lowerCamelCase: user_id — userId,
+// AvatarURL — avatarUrl
+func protoName(field string) string {
+	return strcase.ToLowerCamel(strcase.ToSnake(field))
+}
+
+// protoGoName возвращает имя Go-поля, которое protoc-gen-go создает для поля
+// proto с именем protoName(field): line2 — Line2, userId — UserId
+func protoGoName(field string) string {
+	s := protoName(field)
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' && i == 0:
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLower(s[i+1]):
+			// Подчеркивание перед строчной буквой опускается
+		case c >= '0' && c <= '9':
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isLower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	name := string(b)
+	if protoMethods[name] {
+		name += "_"
+	}
+	return name
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+// canonicalizeNames приводит имена сущностей, полей, связей и объектов-значений
+// к экспортируемым Go-идентификаторам, чтобы остальные имена выводились из них
+func canonicalizeNames(config *domain.ProjectConfig) {
+	objects := make(map[string]string, len(config.ValueObjects))
+	for i := range config.ValueObjects {
+		object := &config.ValueObjects[i]
+		object.Name = canonicalName(object.Name)
+		objects[object.Name] = object.Name
+		canonicalizeFields(object.Fields, nil)
+	}
+	for i := range config.Entities {
+		entity := &config.Entities[i]
+		entity.Name = canonicalName(entity.Name)
+		if entity.ID != nil {
+			entity.ID.Field = canonicalName(entity.ID.Field)
+		}
+		canonicalizeFields(entity.Fields, objects)
+		for j := range entity.Relations {
+			rel := &entity.Relations[j]
+			rel.Entity = canonicalName(rel.Entity)
+			rel.ForeignKey = canonicalName(rel.ForeignKey)
+		}
+	}
+}
+
+// canonicalizeFields приводит имена полей и ссылки на объекты-значения
+func canonicalizeFields(fields []domain.Field, objects map[string]string) {
+	for i := range fields {
+		field := &fields[i]
+		field.Name = canonicalName(field.Name)
+		if name, ok := objects[canonicalName(field.Type)]; ok {
+			field.Type = name
+		}
+	}
+}
+
+// canonicalName возвращает goName непустого имени; имя, которое не удается
+// привести к идентификатору, остается как есть, чтобы валидатор сообщил о нем
+func canonicalName(name string) string {
+	if strings.TrimSpace(name) == "" {
+		return name
+	}
+	if canonical := goName(name); token.IsIdentifier(canonical) {
+		return canonical
+	}
+	return name
+}
+
+// namingFuncs возвращает функции шаблонов для имен в Go, SQL, MongoDB и proto
+func namingFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"GoVar":       goVar,
+		"GoPackage":   goPackage,
+		"SQLName":     sqlName,
+		"Column":      columnName,
+		"JSONName":    jsonName,
+		"BSONName":    bsonName,
+		"ProtoName":   protoName,
+		"ProtoGoName": protoGoName,
+	}
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import "testing"
+
+func TestFieldNames(t *testing.T) {
+	tests := []struct {
+		field, column, bson, proto, protoGo string
+	}{
+		{"Email", "email", "email", "email", "Email"},
+		{"AvatarURL", "avatar_url", "avatar_url", "avatarUrl", "AvatarUrl"},
+		{"UserID", "user_id", "user_id", "userId", "UserId"},
+		{"CreatedAt", "created_at", "created_at", "createdAt", "CreatedAt"},
+		{"Line2", "line_2", "line_2", "line2", "Line2"},
+		{"Order", `"order"`, "order", "order", "Order"},
+		{"String", "string", "string", "string", "String_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := columnName(tt.field); got != tt.column {
+				t.Errorf("columnName() = %s, want %s", got, tt.column)
+			}
+			if got := bsonName(tt.field); got != tt.bson {
+				t.Errorf("bsonName() = %s, want %s", got, tt.bson)
+			}
+			if got := protoName(tt.field); got != tt.proto {
+				t.Errorf("protoName() = %s, want %s", got, tt.proto)
+			}
+			if got := protoGoName(tt.field); got != tt.protoGo {
+				t.Errorf("protoGoName() = %s, want %s", got, tt.protoGo)
+			}
+		})
+	}
+}
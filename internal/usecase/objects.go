@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+	"github.com/iancoleman/strcase"
+)
+
+var supportedStorages = []string{domain.StorageColumns, domain.StorageJSONB}
+
+// Пакет proto, в котором объявлены сообщения объектов-значений
+const objectProtoPackage = "valueobject"
+
+// column — колонка Postgres и путь к значению в структуре сущности
+type column struct {
+	Name  string
+	Path  string
+	Field domain.Field
+}
+
+// registerObject регистрирует объект-значение как тип поля. Типы его полей
+// должны быть зарегистрированы раньше
+func (r *typeRegistry) registerObject(object domain.ValueObject) error {
+	values := make([]string, 0, len(object.Fields))
+	for _, f := range object.Fields {
+		spec, err := r.lookup(f.Type)
+		if err != nil {
+			return fmt.Errorf("value object %s: %w", object.Name, err)
+		}
+		if !f.Nullable {
+			values = append(values, fmt.Sprintf("%s: %s", f.Name, ruleTestValue(f, spec)))
+		}
+	}
+
+	convert := strcase.ToLowerCamel(object.Name)
+	spec := domain.TypeSpec{
+		Name:        object.Name,
+		GoType:      object.Name,
+		Mongo:       "object",
+		Proto:       objectProtoPackage + "." + object.Name,
+		ProtoImport: "value_objects.proto",
+		ToProto:     convert + "ToProto(%s)",
+		FromProto:   convert + "FromProto(%s)",
+		OpenAPI:     "object",
+		TestValue:   fmt.Sprintf("domain.%s{%s}", object.Name, strings.Join(values, ", ")),
+		Fields:      object.Fields,
+	}
+	// Преобразования объекта возвращают ошибку, если ее вернуло
+	// преобразование одного из полей
+	spec.ToProtoError, spec.FromProtoError = true, true
+	if object.Storage == domain.StorageJSONB {
+		spec.Postgres = "JSONB"
+		spec.SQLWrap = "jsonColumn{%s}"
+	} else {
+		spec.Flatten = true
+	}
+	r.types[object.Name] = spec
+	return nil
+}
+
+// postgresColumns раскладывает поля по колонкам таблицы: поля объектов-значений
+// с хранением columns превращаются в колонки с префиксом имени поля
+func (r *typeRegistry) postgresColumns(fields []domain.Field) ([]column, error) {
+	var columns []column
+	for _, f := range fields {
+		spec, err := r.lookup(f.Type)
+		if err != nil {
+			return nil, err
+		}
+		if !spec.Flatten {
+			columns = append(columns, column{Name: columnName(f.Name), Path: f.Name, Field: f})
+			continue
+		}
+		for _, sub := range spec.Fields {
+			columns = append(columns, column{
+				Name:  sqlName(strcase.ToSnake(f.Name) + "_" + strcase.ToSnake(sub.Name)),
+				Path:  f.Name + "." + sub.Name,
+				Field: sub,
+			})
+		}
+	}
+	return columns, nil
+}
+
+// flatten заменяет поля объектов-значений их полями, чтобы собрать импорты
+// кода, который заполняет объекты целиком
+func (r *typeRegistry) flatten(fields []domain.Field) ([]domain.Field, error) {
+	var result []domain.Field
+	for _, f := range fields {
+		spec, err := r.lookup(f.Type)
+		if err != nil {
+			return nil, err
+		}
+		if spec.Fields != nil {
+			result = append(result, spec.Fields...)
+		} else {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+// objectFields возвращает поля всех объектов-значений проекта
+func objectFields(objects []domain.ValueObject) []domain.Field {
+	var fields []domain.Field
+	for _, object := range objects {
+		fields = append(fields, object.Fields...)
+	}
+	return fields
+}
+
+// checkObjects проверяет объявления объектов-значений и регистрирует в реестре
+// корректные из них
+func checkObjects(report func(domain.Severity, string, string, string), types *typeRegistry, config *domain.ProjectConfig) {
+	names := make(map[string]bool, len(config.ValueObjects))
+	for i, object := range config.ValueObjects {
+		path := fmt.Sprintf("value_objects[%d]", i)
+		checkIdentifier(report, path+".name", "value object", object.Name)
+		valid := token.IsIdentifier(object.Name)
+
+		if _, err := types.lookup(object.Name); err == nil || names[object.Name] || isEntityName(config, object.Name) {
+			report(domain.SeverityError, path+".name", fmt.Sprintf("value object %q clashes with a declared type, value object or entity", object.Name), "value object names must be unique")
+			valid = false
+		}
+		names[object.Name] = true
+
+		if object.Storage != "" && !contains(supportedStorages, object.Storage) {
+			report(domain.SeverityError, path+".storage", fmt.Sprintf("unknown storage %q", object.Storage), suggestOneOf(object.Storage, supportedStorages))
+		}
+		if len(object.Fields) == 0 {
+			report(domain.SeverityError, path+".fields", fmt.Sprintf("value object %q has no fields", object.Name), "")
+			valid = false
+		}
+
+		fieldNames := make(map[string]bool, len(object.Fields))
+		for j, field := range object.Fields {
+			fieldPath := fmt.Sprintf("%s.fields[%d]", path, j)
+			checkIdentifier(report, fieldPath+".name", "field", field.Name)
+			if fieldNames[field.Name] {
+				report(domain.SeverityError, fieldPath+".name", fmt.Sprintf("duplicate field %q", field.Name), "field names must be unique within a value object")
+			}
+			fieldNames[field.Name] = true
+
+			if field.Required && field.Nullable {
+				report(domain.SeverityError, fieldPath+".nullable", fmt.Sprintf("field %q cannot be both required and nullable", field.Name), `remove "required" or "nullable"`)
+			}
+
+			spec, err := types.lookup(field.Type)
+			switch {
+			case field.Type == domain.EnumType || len(field.Values) > 0:
+				report(domain.SeverityError, fieldPath+".type", fmt.Sprintf("enum field %q is not supported in value objects", field.Name), `use a string field with "one_of"`)
+				valid = false
+			case containsObject(config.ValueObjects, field.Type):
+				report(domain.SeverityError, fieldPath+".type", fmt.Sprintf("value object %q cannot contain value object %q", object.Name, field.Type), "move the nested fields into the value object")
+				valid = false
+			case err != nil:
+				report(domain.SeverityError, fieldPath+".type", err.Error(), suggestType(types, field.Type))
+				valid = false
+			default:
+				checkRules(report, spec, fieldPath, field)
+			}
+		}
+
+		if valid {
+			if err := types.registerObject(object); err != nil {
+				report(domain.SeverityError, path, err.Error(), "")
+			}
+		}
+
+		if !objectUsed(config.Entities, object.Name) {
+			report(domain.SeverityWarning, path, fmt.Sprintf("value object %q is not used by any entity", object.Name), "remove it or reference it in a field type")
+		}
+	}
+}
+
+func objectUsed(entities []domain.Entity, name string) bool {
+	for _, entity := range entities {
+		for _, field := range entity.Fields {
+			if field.Type == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsObject(objects []domain.ValueObject, name string) bool {
+	for _, object := range objects {
+		if object.Name == name {
+			return true
+		}
+	}
+	return false
+}
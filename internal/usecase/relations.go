@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+	"github.com/iancoleman/strcase"
+)
+
+// resolveRelations приводит связи сущностей к каноничному виду: has_many
+// разворачивается в belongs_to на стороне дочерней сущности, заполняются
+// значения по умолчанию и добавляются поля внешних ключей с типом ключа
+// связанной сущности. Ключи и имена сущностей должны быть уже разрешены
+func resolveRelations(config *domain.ProjectConfig) error {
+	index := make(map[string]int, len(config.Entities))
+	for i, entity := range config.Entities {
+		index[entity.Name] = i
+	}
+
+	for i := range config.Entities {
+		parent := config.Entities[i].Name
+		for k := range config.Entities[i].Relations {
+			rel := &config.Entities[i].Relations[k]
+			if _, ok := index[rel.Entity]; !ok {
+				return fmt.Errorf("entity %s: relation to unknown entity %s", parent, rel.Entity)
+			}
+			if rel.Type != domain.RelationHasMany {
+				continue
+			}
+			if rel.ForeignKey == "" {
+				rel.ForeignKey = strcase.ToCamel(parent) + "ID"
+			}
+
+			child := &config.Entities[index[rel.Entity]]
+			if !hasRelation(*child, domain.RelationBelongsTo, parent) {
+				child.Relations = append(child.Relations, domain.Relation{
+					Type:       domain.RelationBelongsTo,
+					Entity:     parent,
+					ForeignKey: rel.ForeignKey,
+					OnDelete:   rel.OnDelete,
+				})
+			}
+		}
+	}
+
+	for i := range config.Entities {
+		entity := &config.Entities[i]
+		for k := range entity.Relations {
+			rel := &entity.Relations[k]
+			target := config.Entities[index[rel.Entity]]
+			rel.Key, rel.Strategy = target.ID.Key, target.ID.Strategy
+			rel.Plural, rel.Table, rel.Route = target.Plural, target.Table, target.Route
+			switch rel.Type {
+			case domain.RelationBelongsTo:
+				if rel.ForeignKey == "" {
+					rel.ForeignKey = strcase.ToCamel(rel.Entity) + "ID"
+				}
+				if rel.OnDelete == "" {
+					rel.OnDelete = "CASCADE"
+				}
+				if !hasField(*entity, rel.ForeignKey) {
+					// При ON DELETE SET NULL внешний ключ должен допускать NULL
+					nullable := strings.EqualFold(rel.OnDelete, "SET NULL")
+					entity.Fields = append(entity.Fields, domain.Field{
+						Name:     rel.ForeignKey,
+						Type:     rel.Key.Type,
+						Required: !nullable,
+						Nullable: nullable,
+					})
+				}
+			case domain.RelationHasMany:
+			case domain.RelationManyToMany:
+				if rel.Entity == entity.Name {
+					return fmt.Errorf("entity %s: many_to_many relation to itself is not supported", entity.Name)
+				}
+				if rel.JoinTable == "" {
+					rel.JoinTable = entity.Table + "_" + rel.Table
+				}
+			default:
+				return fmt.Errorf("entity %s: unknown relation type %q", entity.Name, rel.Type)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sortEntities упорядочивает сущности так, чтобы таблицы, на которые
+// ссылаются внешние ключи, создавались раньше ссылающихся на них
+func sortEntities(entities []domain.Entity) ([]domain.Entity, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	index := make(map[string]int, len(entities))
+	for i, entity := range entities {
+		index[entity.Name] = i
+	}
+
+	state := make([]int, len(entities))
+	sorted := make([]domain.Entity, 0, len(entities))
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("cyclic belongs_to relations: %s -> %s", strings.Join(path, " -> "), entities[i].Name)
+		}
+
+		state[i] = visiting
+		path = append(path, entities[i].Name)
+		for _, rel := range entities[i].Relations {
+			if rel.Type != domain.RelationBelongsTo || rel.Entity == entities[i].Name {
+				continue
+			}
+			if j, ok := index[rel.Entity]; ok {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+
+		sorted = append(sorted, entities[i])
+		return nil
+	}
+
+	for i := range entities {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+func hasRelation(entity domain.Entity, relType, target string) bool {
+	for _, rel := range entity.Relations {
+		if rel.Type == relType && rel.Entity == target {
+			return true
+		}
+	}
+	return false
+}
+
+func hasField(entity domain.Entity, name string) bool {
+	for _, field := range entity.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
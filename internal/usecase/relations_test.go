@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+// resolveTestConfig разрешает имена, ключи и связи так же, как Render
+func resolveTestConfig(t *testing.T, config *domain.ProjectConfig) error {
+	t.Helper()
+	canonicalizeNames(config)
+	resolveNames(config)
+	if err := resolveKeys(config); err != nil {
+		t.Fatal(err)
+	}
+	return resolveRelations(config)
+}
+
+func testEntity(name string, relations ...domain.Relation) domain.Entity {
+	return domain.Entity{
+		Name:      name,
+		Fields:    []domain.Field{{Name: "Title", Type: "string"}},
+		Relations: relations,
+	}
+}
+
+func TestResolveRelations(t *testing.T) {
+	tests := []struct {
+		name     string
+		entities []domain.Entity
+		// entity и field — внешний ключ, который должен появиться
+		entity   string
+		field    domain.Field
+		relation domain.Relation
+		err      string
+	}{
+		{
+			name: "belongs_to adds a required foreign key",
+			entities: []domain.Entity{
+				testEntity("User"),
+				testEntity("Post", domain.Relation{Type: domain.RelationBelongsTo, Entity: "User"}),
+			},
+			entity: "Post",
+			field:  domain.Field{Name: "UserID", Type: "string", Required: true},
+			relation: domain.Relation{
+				Type: domain.RelationBelongsTo, Entity: "User", ForeignKey: "UserID", OnDelete: "CASCADE",
+				Strategy: domain.IDUUID, Plural: "Users", Table: "users", Route: "users",
+			},
+		},
+		{
+			name: "has_many is expanded on the child",
+			entities: []domain.Entity{
+				testEntity("User", domain.Relation{Type: domain.RelationHasMany, Entity: "Post", OnDelete: "SET NULL"}),
+				testEntity("Post"),
+			},
+			entity: "Post",
+			field:  domain.Field{Name: "UserID", Type: "string", Nullable: true},
+			relation: domain.Relation{
+				Type: domain.RelationBelongsTo, Entity: "User", ForeignKey: "UserID", OnDelete: "SET NULL",
+				Strategy: domain.IDUUID, Plural: "Users", Table: "users", Route: "users",
+			},
+		},
+		{
+			name: "foreign key takes the key type of the parent",
+			entities: []domain.Entity{
+				{Name: "Order", ID: &domain.IDConfig{Strategy: domain.IDBigSerial}},
+				testEntity("OrderItem", domain.Relation{Type: domain.RelationBelongsTo, Entity: "Order", ForeignKey: "ParentID"}),
+			},
+			entity: "OrderItem",
+			field:  domain.Field{Name: "ParentID", Type: "int64", Required: true},
+			relation: domain.Relation{
+				Type: domain.RelationBelongsTo, Entity: "Order", ForeignKey: "ParentID", OnDelete: "CASCADE",
+				Strategy: domain.IDBigSerial, Plural: "Orders", Table: "orders", Route: "orders",
+			},
+		},
+		{
+			name: "unknown entity",
+			entities: []domain.Entity{
+				testEntity("Post", domain.Relation{Type: domain.RelationBelongsTo, Entity: "Author"}),
+			},
+			err: "relation to unknown entity Author",
+		},
+		{
+			name: "many_to_many to itself",
+			entities: []domain.Entity{
+				testEntity("Tag", domain.Relation{Type: domain.RelationManyToMany, Entity: "Tag"}),
+			},
+			err: "many_to_many relation to itself",
+		},
+		{
+			name: "unknown relation type",
+			entities: []domain.Entity{
+				testEntity("User"),
+				testEntity("Post", domain.Relation{Type: "has_one", Entity: "User"}),
+			},
+			err: `unknown relation type "has_one"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &domain.ProjectConfig{Entities: tt.entities}
+			err := resolveTestConfig(t, config)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, entity := range config.Entities {
+				if entity.Name != tt.entity {
+					continue
+				}
+				field, ok := findField(entity, tt.field.Name)
+				if !ok || !reflect.DeepEqual(field, tt.field) {
+					t.Errorf("field = %+v, want %+v", field, tt.field)
+				}
+				if len(entity.Relations) != 1 {
+					t.Fatalf("relations = %+v, want one", entity.Relations)
+				}
+				rel := entity.Relations[0]
+				rel.Key = domain.Field{}
+				if !reflect.DeepEqual(rel, tt.relation) {
+					t.Errorf("relation = %+v, want %+v", rel, tt.relation)
+				}
+			}
+		})
+	}
+}
+
+func TestForeignKeyColumnType(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent domain.Entity
+		want   string
+	}{
+		{"uuid key in a string field", domain.Entity{Name: "User"}, "VARCHAR(36)"},
+		{"uuid key", domain.Entity{Name: "User", Fields: []domain.Field{{Name: "ID", Type: "uuid"}}}, "UUID"},
+		{"ulid key", domain.Entity{Name: "User", ID: &domain.IDConfig{Strategy: domain.IDULID}, Fields: []domain.Field{{Name: "ID", Type: "string"}}}, "CHAR(26)"},
+		{"serial key", domain.Entity{Name: "User", ID: &domain.IDConfig{Strategy: domain.IDSerial}}, "INTEGER"},
+		{"natural key", domain.Entity{Name: "User", ID: &domain.IDConfig{Strategy: domain.IDNatural, Field: "Login"}, Fields: []domain.Field{{Name: "Login", Type: "string"}}}, "VARCHAR(255)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &domain.ProjectConfig{Entities: []domain.Entity{
+				tt.parent,
+				testEntity("Post", domain.Relation{Type: domain.RelationBelongsTo, Entity: "User"}),
+			}}
+			if err := resolveTestConfig(t, config); err != nil {
+				t.Fatal(err)
+			}
+			types, err := newTypeRegistry(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			post := config.Entities[1]
+			field, _ := findField(post, "UserID")
+			got, err := types.columnType(post, field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("columnType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortEntities(t *testing.T) {
+	belongsTo := func(name string, parents ...string) domain.Entity {
+		entity := domain.Entity{Name: name}
+		for _, parent := range parents {
+			entity.Relations = append(entity.Relations, domain.Relation{Type: domain.RelationBelongsTo, Entity: parent})
+		}
+		return entity
+	}
+	tests := []struct {
+		name     string
+		entities []domain.Entity
+		want     []string
+		err      string
+	}{
+		{
+			name:     "no relations keep their order",
+			entities: []domain.Entity{belongsTo("B"), belongsTo("A")},
+			want:     []string{"B", "A"},
+		},
+		{
+			name:     "parents come first",
+			entities: []domain.Entity{belongsTo("OrderItem", "Order", "Product"), belongsTo("Order", "User"), belongsTo("Product"), belongsTo("User")},
+			want:     []string{"User", "Order", "Product", "OrderItem"},
+		},
+		{
+			name:     "self reference is not a cycle",
+			entities: []domain.Entity{belongsTo("Category", "Category")},
+			want:     []string{"Category"},
+		},
+		{
+			name:     "has_many does not order",
+			entities: []domain.Entity{{Name: "User", Relations: []domain.Relation{{Type: domain.RelationHasMany, Entity: "Post"}}}, belongsTo("Post")},
+			want:     []string{"User", "Post"},
+		},
+		{
+			name:     "cycle",
+			entities: []domain.Entity{belongsTo("A", "B"), belongsTo("B", "C"), belongsTo("C", "A")},
+			err:      "cyclic belongs_to relations: A -> B -> C -> A",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, err := sortEntities(tt.entities)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, entity := range sorted {
+				names = append(names, entity.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("sortEntities() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+	"github.com/iancoleman/strcase"
+)
+
+// Go-типы, к которым применимы правила min и max
+var numberTypes = []string{"int", "int32", "int64", "float32", "float64"}
+
+// fieldRule — проверка значения поля в сгенерированном методе Validate
+type fieldRule struct {
+	// Check — Go-выражение, истинное при нарушении правила
+	Check   string
+	Message string
+}
+
+func isText(spec domain.TypeSpec) bool {
+	return spec.GoType == "string"
+}
+
+func isNumber(spec domain.TypeSpec) bool {
+	return contains(numberTypes, spec.GoType)
+}
+
+func isInteger(spec domain.TypeSpec) bool {
+	return isNumber(spec) && strings.HasPrefix(spec.GoType, "int")
+}
+
+// hasRules сообщает, что для поля генерируется проверка значения
+func hasRules(f domain.Field) bool {
+	return f.Required || f.Min != nil || f.Max != nil || f.MinLength != nil || f.MaxLength != nil ||
+		f.Pattern != "" || f.Email || f.URL || len(f.OneOf) > 0 || len(f.Values) > 0
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// requiredCheck возвращает условие отсутствия значения или пустую строку,
+// если у типа нет различимого пустого значения
+func requiredCheck(spec domain.TypeSpec, expr string) string {
+	switch {
+	case spec.Zero != "":
+		return fmt.Sprintf("%s == %s", expr, spec.Zero)
+	case isReference(spec.GoType):
+		return fmt.Sprintf("len(%s) == 0", expr)
+	case spec.GoType == "time.Time" || spec.GoType == "decimal.Decimal":
+		if strings.HasPrefix(expr, "*") {
+			expr = "(" + expr + ")"
+		}
+		return expr + ".IsZero()"
+	}
+	return ""
+}
+
+// patternVar возвращает имя переменной скомпилированного pattern поля
+func patternVar(entity string, f domain.Field) string {
+	return strcase.ToLowerCamel(entity) + f.Name + "Pattern"
+}
+
+// quoteSQL записывает строку как SQL-литерал
+func quoteSQL(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// ruleFuncs возвращает функции шаблонов для правил проверки полей
+func ruleFuncs(registry func() *typeRegistry) map[string]interface{} {
+	lookup := func(f domain.Field) (domain.TypeSpec, error) {
+		return registry().lookup(f.Type)
+	}
+
+	return map[string]interface{}{
+		"HasRules":   hasRules,
+		"PatternVar": patternVar,
+		// FieldRules возвращает проверки значения expr для метода Validate
+		"FieldRules": func(entity string, f domain.Field, expr string) ([]fieldRule, error) {
+			spec, err := lookup(f)
+			if err != nil {
+				return nil, err
+			}
+			var rules []fieldRule
+			if f.Required {
+				if check := requiredCheck(spec, expr); check != "" {
+					rules = append(rules, fieldRule{check, "is required"})
+				}
+			}
+			if f.Min != nil {
+				rules = append(rules, fieldRule{fmt.Sprintf("%s < %s", expr, formatNumber(*f.Min)), "must be at least " + formatNumber(*f.Min)})
+			}
+			if f.Max != nil {
+				rules = append(rules, fieldRule{fmt.Sprintf("%s > %s", expr, formatNumber(*f.Max)), "must be at most " + formatNumber(*f.Max)})
+			}
+			if f.MinLength != nil {
+				rules = append(rules, fieldRule{fmt.Sprintf("utf8.RuneCountInString(%s) < %d", expr, *f.MinLength), fmt.Sprintf("must be at least %d characters", *f.MinLength)})
+			}
+			if f.MaxLength != nil {
+				rules = append(rules, fieldRule{fmt.Sprintf("utf8.RuneCountInString(%s) > %d", expr, *f.MaxLength), fmt.Sprintf("must be at most %d characters", *f.MaxLength)})
+			}
+			if f.Pattern != "" {
+				rules = append(rules, fieldRule{fmt.Sprintf("!%s.MatchString(%s)", patternVar(entity, f), expr), "must match pattern " + f.Pattern})
+			}
+			if f.Email {
+				rules = append(rules, fieldRule{fmt.Sprintf("!isEmail(%s)", expr), "must be a valid email address"})
+			}
+			if f.URL {
+				rules = append(rules, fieldRule{fmt.Sprintf("!isURL(%s)", expr), "must be a valid URL"})
+			}
+			if len(f.OneOf) > 0 {
+				values := make([]string, len(f.OneOf))
+				for i, value := range f.OneOf {
+					values[i] = fmt.Sprintf("%s != %q", expr, value)
+				}
+				rules = append(rules, fieldRule{strings.Join(values, " && "), "must be one of: " + strings.Join(f.OneOf, ", ")})
+			}
+			if len(f.Values) > 0 {
+				// Колонка перечисления не хранит пустую строку, поэтому незаданное
+				// значение допустимо только у nullable-поля
+				if strings.HasPrefix(expr, "*") {
+					expr = "(" + expr + ")"
+				}
+				rules = append(rules, fieldRule{fmt.Sprintf("!%s.IsValid()", expr), "must be one of: " + strings.Join(f.Values, ", ")})
+			}
+			return rules, nil
+		},
+		// ValidationImports возвращает пакеты, которые использует метод Validate
+		"ValidationImports": func(fields []domain.Field) []string {
+			var imports []string
+			for _, f := range fields {
+				if f.Pattern != "" && !contains(imports, "regexp") {
+					imports = append(imports, "regexp")
+				}
+				if (f.MinLength != nil || f.MaxLength != nil) && !contains(imports, "unicode/utf8") {
+					imports = append(imports, "unicode/utf8")
+				}
+			}
+			sort.Strings(imports)
+			return imports
+		},
+		// BindingTag возвращает тег binding, которым gin проверяет тело запроса
+		"BindingTag": func(f domain.Field) (string, error) {
+			spec, err := lookup(f)
+			if err != nil {
+				return "", err
+			}
+			return bindingTag(f, spec), nil
+		},
+		// PostgresCheck возвращает ограничение CHECK колонки поля
+		"PostgresCheck": func(column string, f domain.Field) (string, error) {
+			spec, err := lookup(f)
+			if err != nil {
+				return "", err
+			}
+			var checks []string
+			if f.Min != nil {
+				checks = append(checks, fmt.Sprintf("%s >= %s", column, formatNumber(*f.Min)))
+			}
+			if f.Max != nil {
+				checks = append(checks, fmt.Sprintf("%s <= %s", column, formatNumber(*f.Max)))
+			}
+			if f.MinLength != nil {
+				checks = append(checks, fmt.Sprintf("char_length(%s) >= %d", column, *f.MinLength))
+			}
+			if f.MaxLength != nil {
+				checks = append(checks, fmt.Sprintf("char_length(%s) <= %d", column, *f.MaxLength))
+			}
+			if f.Pattern != "" {
+				checks = append(checks, fmt.Sprintf("%s ~ %s", column, quoteSQL(f.Pattern)))
+			}
+			if len(f.OneOf) > 0 && isText(spec) {
+				values := make([]string, len(f.OneOf))
+				for i, value := range f.OneOf {
+					values[i] = quoteSQL(value)
+				}
+				checks = append(checks, fmt.Sprintf("%s IN (%s)", column, strings.Join(values, ", ")))
+			}
+			if len(checks) == 0 {
+				return "", nil
+			}
+			return " CHECK (" + strings.Join(checks, " AND ") + ")", nil
+		},
+		// ProtoRules возвращает аннотации protovalidate поля запроса
+		"ProtoRules": func(f domain.Field) (string, error) {
+			spec, err := lookup(f)
+			if err != nil {
+				return "", err
+			}
+			return protoRules(f, spec), nil
+		},
+		// ProtoUpdateRules — аннотации поля запроса Update без required: поля
+		// вне update_mask не передаются, а обязательность полей сущности после
+		// обновления проверяет Validate
+		"ProtoUpdateRules": func(f domain.Field) (string, error) {
+			spec, err := lookup(f)
+			if err != nil {
+				return "", err
+			}
+			f.Required = false
+			return protoRules(f, spec), nil
+		},
+		"HasProtoRules": func(fields []domain.Field) (bool, error) {
+			for _, f := range fields {
+				spec, err := lookup(f)
+				if err != nil {
+					return false, err
+				}
+				if protoRules(f, spec) != "" {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+		// MongoValidator возвращает $jsonSchema коллекции или пустую строку,
+		// если у полей нет правил
+		"MongoValidator": func(fields []domain.Field) (string, error) {
+			return mongoValidator(fields)
+		},
+		// RuleTestValue возвращает тестовое значение, проходящее правила поля
+		"RuleTestValue": func(f domain.Field) (string, error) {
+			spec, err := lookup(f)
+			if err != nil {
+				return "", err
+			}
+			return ruleTestValue(f, spec), nil
+		},
+	}
+}
+
+func bindingTag(f domain.Field, spec domain.TypeSpec) string {
+	var rules []string
+	if f.Required {
+		rules = append(rules, "required")
+	}
+	if isNumber(spec) {
+		if f.Min != nil {
+			rules = append(rules, "gte="+formatNumber(*f.Min))
+		}
+		if f.Max != nil {
+			rules = append(rules, "lte="+formatNumber(*f.Max))
+		}
+	}
+	if isText(spec) {
+		if f.MinLength != nil {
+			rules = append(rules, fmt.Sprintf("min=%d", *f.MinLength))
+		}
+		if f.MaxLength != nil {
+			rules = append(rules, fmt.Sprintf("max=%d", *f.MaxLength))
+		}
+		if f.Email {
+			rules = append(rules, "email")
+		}
+		if f.URL {
+			rules = append(rules, "url")
+		}
+		// Значения oneof разделяются пробелами, остальные случаи проверяет Validate
+		if len(f.OneOf) > 0 && !strings.ContainsAny(strings.Join(f.OneOf, ""), " ,'\"") {
+			rules = append(rules, "oneof="+strings.Join(f.OneOf, " "))
+		}
+	}
+	if len(f.Values) > 0 && !strings.ContainsAny(strings.Join(f.Values, ""), " ,'\"") {
+		rules = append(rules, "oneof="+strings.Join(f.Values, " "))
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+	if f.Nullable {
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return strings.Join(rules, ",")
+}
+
+func protoRules(f domain.Field, spec domain.TypeSpec) string {
+	var options []string
+	if f.Required {
+		options = append(options, "(buf.validate.field).required = true")
+	}
+
+	var rules []string
+	var kind string
+	switch {
+	case spec.Proto == "string" && isText(spec):
+		kind = "string"
+		if f.MinLength != nil {
+			rules = append(rules, fmt.Sprintf("min_len: %d", *f.MinLength))
+		}
+		if f.MaxLength != nil {
+			rules = append(rules, fmt.Sprintf("max_len: %d", *f.MaxLength))
+		}
+		if f.Pattern != "" {
+			rules = append(rules, "pattern: "+strconv.Quote(f.Pattern))
+		}
+		if f.Email {
+			rules = append(rules, "email: true")
+		}
+		if f.URL {
+			rules = append(rules, "uri: true")
+		}
+		if len(f.OneOf) > 0 {
+			values := make([]string, len(f.OneOf))
+			for i, value := range f.OneOf {
+				values[i] = strconv.Quote(value)
+			}
+			rules = append(rules, "in: ["+strings.Join(values, ", ")+"]")
+		}
+	case len(f.Values) > 0:
+		// Нулевое значение _UNSPECIFIED допустимо только у nullable-поля
+		kind = "enum"
+		rules = append(rules, "defined_only: true")
+		if !f.Required && !f.Nullable {
+			rules = append(rules, "not_in: [0]")
+		}
+	case isNumber(spec) && contains([]string{"int32", "int64", "float", "double"}, spec.Proto):
+		kind = spec.Proto
+		if f.Min != nil {
+			rules = append(rules, "gte: "+formatNumber(*f.Min))
+		}
+		if f.Max != nil {
+			rules = append(rules, "lte: "+formatNumber(*f.Max))
+		}
+	}
+	if len(rules) > 0 {
+		options = append(options, fmt.Sprintf("(buf.validate.field).%s = {%s}", kind, strings.Join(rules, ", ")))
+	}
+
+	if len(options) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(options, ", ") + "]"
+}
+
+type mongoProperty struct {
+	Minimum   *float64      `json:"minimum,omitempty"`
+	Maximum   *float64      `json:"maximum,omitempty"`
+	MinLength *int          `json:"minLength,omitempty"`
+	MaxLength *int          `json:"maxLength,omitempty"`
+	Pattern   string        `json:"pattern,omitempty"`
+	Enum      []interface{} `json:"enum,omitempty"`
+}
+
+type mongoSchema struct {
+	BSONType   string                   `json:"bsonType"`
+	Required   []string                 `json:"required,omitempty"`
+	Properties map[string]mongoProperty `json:"properties,omitempty"`
+}
+
+func mongoValidator(fields []domain.Field) (string, error) {
+	schema := mongoSchema{BSONType: "object", Properties: make(map[string]mongoProperty)}
+	for _, f := range fields {
+		name := bsonName(f.Name)
+		if f.Required {
+			schema.Required = append(schema.Required, name)
+		}
+		property := mongoProperty{Minimum: f.Min, Maximum: f.Max, MinLength: f.MinLength, MaxLength: f.MaxLength, Pattern: f.Pattern}
+		for _, value := range f.OneOf {
+			property.Enum = append(property.Enum, value)
+		}
+		if len(f.Values) > 0 {
+			for _, value := range f.Values {
+				property.Enum = append(property.Enum, value)
+			}
+			// Незаданное значение nullable-перечисления хранится как null
+			if f.Nullable {
+				property.Enum = append(property.Enum, nil)
+			}
+		}
+		if property.Minimum != nil || property.Maximum != nil || property.MinLength != nil || property.MaxLength != nil || property.Pattern != "" || len(property.Enum) > 0 {
+			schema.Properties[name] = property
+		}
+	}
+	if len(schema.Required) == 0 && len(schema.Properties) == 0 {
+		return "", nil
+	}
+
+	data, err := json.MarshalIndent(map[string]mongoSchema{"$jsonSchema": schema}, "    ", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func ruleTestValue(f domain.Field, spec domain.TypeSpec) string {
+	switch {
+	case len(f.OneOf) > 0 && isText(spec):
+		return strconv.Quote(f.OneOf[0])
+	case f.Email && isText(spec):
+		return `"user@example.com"`
+	case f.URL && isText(spec):
+		return `"https://example.com"`
+	case isText(spec) && (f.MinLength != nil || f.MaxLength != nil):
+		// Строка из "a" длиной между min_length и max_length
+		length := 10
+		if f.MaxLength != nil && *f.MaxLength < length {
+			length = *f.MaxLength
+		}
+		if f.MinLength != nil && *f.MinLength > length {
+			length = *f.MinLength
+		}
+		return strconv.Quote(strings.Repeat("a", length))
+	case isNumber(spec) && f.Min != nil:
+		return formatNumber(*f.Min)
+	case isNumber(spec) && f.Max != nil:
+		return formatNumber(*f.Max)
+	}
+	return spec.TestValue
+}
@@ -0,0 +1,376 @@
+package usecase
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+// Встроенные типы полей. Пользовательские типы из секции types конфигурации
+// регистрируются поверх них и могут их переопределять
+var builtinTypes = []domain.TypeSpec{
+	{Name: "string", GoType: "string", Postgres: "VARCHAR(255)", Mongo: "string", Proto: "string", OpenAPI: "string", TestValue: `"test-value"`, Zero: `""`, Filter: stringFilter},
+	{Name: "int", GoType: "int", Postgres: "BIGINT", Mongo: "long", Proto: "int64", ToProto: "int64(%s)", FromProto: "int(%s)", OpenAPI: "integer", TestValue: "123", Zero: "0", Parse: "strconv.Atoi(%s)", ParseImports: []string{"strconv"}, Filter: orderedFilter},
+	{Name: "int32", GoType: "int32", Postgres: "INTEGER", Mongo: "int", Proto: "int32", OpenAPI: "integer", OpenAPIFormat: "int32", TestValue: "123", Zero: "0", Parse: "func() (int32, error) { v, err := strconv.ParseInt(%s, 10, 32); return int32(v), err }()", ParseImports: []string{"strconv"}, Filter: orderedFilter},
+	{Name: "int64", GoType: "int64", Postgres: "BIGINT", Mongo: "long", Proto: "int64", OpenAPI: "integer", OpenAPIFormat: "int64", TestValue: "123", Zero: "0", Parse: "strconv.ParseInt(%s, 10, 64)", ParseImports: []string{"strconv"}, Filter: orderedFilter},
+	{Name: "float32", GoType: "float32", Postgres: "REAL", Mongo: "double", Proto: "float", OpenAPI: "number", OpenAPIFormat: "float", TestValue: "123.45", Zero: "0", Parse: "func() (float32, error) { v, err := strconv.ParseFloat(%s, 32); return float32(v), err }()", ParseImports: []string{"strconv"}, Filter: orderedFilter},
+	{Name: "float64", GoType: "float64", Postgres: "DOUBLE PRECISION", Mongo: "double", Proto: "double", OpenAPI: "number", OpenAPIFormat: "double", TestValue: "123.45", Zero: "0", Parse: "strconv.ParseFloat(%s, 64)", ParseImports: []string{"strconv"}, Filter: orderedFilter},
+	{Name: "bool", GoType: "bool", Postgres: "BOOLEAN", Mongo: "bool", Proto: "bool", OpenAPI: "boolean", TestValue: "true", Parse: "strconv.ParseBool(%s)", ParseImports: []string{"strconv"}, Filter: []string{"eq", "ne"}},
+	{Name: "time", GoType: "time.Time", Imports: []string{"time"}, Postgres: "TIMESTAMPTZ", Mongo: "date", Proto: "google.protobuf.Timestamp", ProtoImport: "google/protobuf/timestamp.proto", ProtoGoImports: []string{"google.golang.org/protobuf/types/known/timestamppb"}, ToProto: "timestamppb.New(%s)", FromProto: "timeFromProto(%s)", FromProtoError: true, OpenAPI: "string", OpenAPIFormat: "date-time", TestValue: "time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)", Parse: "time.Parse(time.RFC3339, %s)", ParseImports: []string{"time"}, Filter: rangeFilter},
+	{Name: "uuid", GoType: "uuid.UUID", Imports: []string{"github.com/google/uuid"}, Postgres: "UUID", Mongo: "binData", Proto: "string", ProtoGoImports: []string{"github.com/google/uuid"}, ToProto: "%s.String()", FromProto: "uuid.Parse(%s)", FromProtoError: true, OpenAPI: "string", OpenAPIFormat: "uuid", TestValue: "uuid.New()", Zero: "uuid.Nil", Parse: "uuid.Parse(%s)", ParseImports: []string{"github.com/google/uuid"}, Filter: equalityFilter},
+	{Name: "ulid", GoType: "string", Postgres: "CHAR(26)", Mongo: "string", Proto: "string", OpenAPI: "string", OpenAPIFormat: "ulid", TestValue: `"01ARZ3NDEKTSV4RRFFQ69G5FAV"`, Zero: `""`, Filter: orderedFilter},
+	{Name: "decimal", GoType: "decimal.Decimal", Imports: []string{"github.com/shopspring/decimal"}, Postgres: "NUMERIC", Mongo: "decimal", Proto: "string", ProtoGoImports: []string{"github.com/shopspring/decimal"}, ToProto: "%s.String()", FromProto: "decimal.NewFromString(%s)", FromProtoError: true, OpenAPI: "string", OpenAPIFormat: "decimal", TestValue: `decimal.RequireFromString("123.45")`, Parse: "decimal.NewFromString(%s)", ParseImports: []string{"github.com/shopspring/decimal"}, Filter: orderedFilter},
+	{Name: "json", GoType: "map[string]interface{}", Postgres: "JSONB", Mongo: "object", Proto: "google.protobuf.Struct", ProtoImport: "google/protobuf/struct.proto", ProtoGoImports: []string{"google.golang.org/protobuf/types/known/structpb"}, ToProto: "structpb.NewStruct(%s)", ToProtoError: true, FromProto: "%s.AsMap()", OpenAPI: "object", SQLWrap: "jsonColumn{%s}", TestValue: `map[string]interface{}{"key": "value"}`},
+	{Name: "bytes", GoType: "[]byte", Postgres: "BYTEA", Mongo: "binData", Proto: "bytes", OpenAPI: "string", OpenAPIFormat: "byte", TestValue: `[]byte("test-value")`},
+}
+
+// Операторы фильтров списка для встроенных типов: строки сравниваются на
+// равенство и по шаблону like, упорядоченные типы — еще и на больше-меньше
+var (
+	equalityFilter = []string{"eq", "ne", "in"}
+	stringFilter   = []string{"eq", "ne", "in", "like"}
+	orderedFilter  = []string{"eq", "ne", "gt", "gte", "lt", "lte", "in"}
+	rangeFilter    = []string{"eq", "ne", "gt", "gte", "lt", "lte"}
+)
+
+// Типы элементов массивов ("[]string" и т.п.), которые lib/pq читает и
+// записывает через pq.Array без преобразований
+var arrayElements = []string{"string", "int64", "float64", "bool"}
+
+// Модули, которые нужно добавить в go.mod проекта, если их импортирует тип поля
+var typeModules = map[string]string{
+	"github.com/shopspring/decimal": "v1.4.0",
+}
+
+// typeRegistry — единый источник представлений типов полей для всех шаблонов
+type typeRegistry struct {
+	types map[string]domain.TypeSpec
+}
+
+// newTypeRegistry создает реестр со встроенными типами и типами из конфигурации
+func newTypeRegistry(custom []domain.TypeSpec) (*typeRegistry, error) {
+	r := &typeRegistry{types: make(map[string]domain.TypeSpec, len(builtinTypes)+len(custom))}
+	for _, spec := range builtinTypes {
+		r.types[spec.Name] = spec
+	}
+	for _, spec := range custom {
+		if err := r.register(spec); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *typeRegistry) register(spec domain.TypeSpec) error {
+	switch {
+	case spec.Name == "":
+		return fmt.Errorf("type name is required")
+	case spec.GoType == "":
+		return fmt.Errorf("type %q: go_type is required", spec.Name)
+	case spec.Postgres == "":
+		return fmt.Errorf("type %q: postgres is required", spec.Name)
+	}
+	if spec.Mongo == "" {
+		spec.Mongo = "object"
+	}
+	if spec.OpenAPI == "" {
+		spec.OpenAPI = "object"
+	}
+	// Преобразования работают со значениями go_type, поэтому без своих
+	// импортов им доступны импорты типа; лишние удалит форматирование
+	if len(spec.ProtoGoImports) == 0 {
+		spec.ProtoGoImports = spec.Imports
+	}
+	if len(spec.ParseImports) == 0 {
+		spec.ParseImports = spec.Imports
+	}
+	r.types[spec.Name] = spec
+	return nil
+}
+
+func (r *typeRegistry) lookup(name string) (domain.TypeSpec, error) {
+	if spec, ok := r.types[name]; ok {
+		return spec, nil
+	}
+	if elem, ok := strings.CutPrefix(name, "[]"); ok {
+		return r.arrayOf(elem)
+	}
+	return domain.TypeSpec{}, fmt.Errorf("unknown type %q", name)
+}
+
+// arrayOf выводит представления массива из типа его элемента
+func (r *typeRegistry) arrayOf(elem string) (domain.TypeSpec, error) {
+	spec, ok := r.types[elem]
+	if !ok || !contains(arrayElements, elem) {
+		return domain.TypeSpec{}, fmt.Errorf("arrays of %q are not supported", elem)
+	}
+	column := spec.Postgres
+	if elem == "string" {
+		column = "TEXT"
+	}
+	return domain.TypeSpec{
+		Name:       "[]" + elem,
+		GoType:     "[]" + spec.GoType,
+		Postgres:   column + "[]",
+		Mongo:      "array",
+		Proto:      "repeated " + spec.Proto,
+		OpenAPI:    "array",
+		SQLWrap:    "pq.Array(%s)",
+		SQLImports: []string{"github.com/lib/pq"},
+		TestValue:  fmt.Sprintf("[]%s{%s}", spec.GoType, spec.TestValue),
+	}, nil
+}
+
+// uses сообщает, есть ли в проекте поле, тип которого удовлетворяет условию
+func (r *typeRegistry) uses(entities []domain.Entity, match func(domain.TypeSpec) bool) bool {
+	for _, entity := range entities {
+		fields := entity.Fields
+		if entity.ID != nil {
+			fields = append([]domain.Field{entity.ID.Key}, fields...)
+		}
+		for _, field := range fields {
+			spec, err := r.lookup(field.Type)
+			if err != nil {
+				continue
+			}
+			if match(spec) {
+				return true
+			}
+			// Поля объекта-значения
+			for _, sub := range spec.Fields {
+				if spec, err := r.lookup(sub.Type); err == nil && match(spec) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// mongoCodecs сообщает, что сущностям нужен реестр BSON-кодеков из
+// codecs.go: без него драйвер хранит decimal.Decimal как документ
+func (r *typeRegistry) mongoCodecs(entities []domain.Entity) bool {
+	return r.uses(entities, func(spec domain.TypeSpec) bool {
+		return spec.GoType == "decimal.Decimal"
+	})
+}
+
+// names возвращает отсортированные имена зарегистрированных типов
+func (r *typeRegistry) names() []string {
+	names := make([]string, 0, len(r.types))
+	for name := range r.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// typeFuncs возвращает функции шаблонов, работающие с текущим реестром генератора
+func typeFuncs(registry func() *typeRegistry) map[string]interface{} {
+	field := func(get func(domain.TypeSpec) string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			spec, err := registry().lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return get(spec), nil
+		}
+	}
+	convert := func(get func(domain.TypeSpec) string) func(string, string) (string, error) {
+		return func(name, expr string) (string, error) {
+			spec, err := registry().lookup(name)
+			if err != nil {
+				return "", err
+			}
+			if format := get(spec); format != "" {
+				// Разыменование указателя перед вызовом метода берется в скобки
+				if strings.HasPrefix(expr, "*") && strings.Contains(format, "%s.") {
+					expr = "(" + expr + ")"
+				}
+				return fmt.Sprintf(format, expr), nil
+			}
+			return expr, nil
+		}
+	}
+	// failing сообщает, что преобразование типа может вернуть ошибку; для
+	// списка полей — что это верно хотя бы для одного из них
+	failing := func(get func(domain.TypeSpec) bool) func(interface{}) (bool, error) {
+		return func(value interface{}) (bool, error) {
+			var names []string
+			switch v := value.(type) {
+			case string:
+				names = []string{v}
+			case []domain.Field:
+				for _, f := range v {
+					names = append(names, f.Type)
+				}
+			default:
+				return false, fmt.Errorf("unexpected %T", value)
+			}
+			for _, name := range names {
+				spec, err := registry().lookup(name)
+				if err != nil {
+					return false, err
+				}
+				if get(spec) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	}
+	// imports собирает уникальные импорты типов полей, кроме уже подключенных шаблоном
+	imports := func(get func(domain.TypeSpec) []string) func([]domain.Field, ...string) ([]string, error) {
+		return func(fields []domain.Field, exclude ...string) ([]string, error) {
+			seen := make(map[string]bool)
+			for _, path := range exclude {
+				seen[path] = true
+			}
+			var result []string
+			for _, f := range fields {
+				spec, err := registry().lookup(f.Type)
+				if err != nil {
+					return nil, err
+				}
+				for _, path := range get(spec) {
+					if !seen[path] {
+						seen[path] = true
+						result = append(result, path)
+					}
+				}
+			}
+			sort.Strings(result)
+			return result, nil
+		}
+	}
+
+	return map[string]interface{}{
+		"GoType":         field(func(s domain.TypeSpec) string { return s.GoType }),
+		"ToPostgresType": field(func(s domain.TypeSpec) string { return s.Postgres }),
+		"ToMongoType":    field(func(s domain.TypeSpec) string { return s.Mongo }),
+		"ToProtoType":    field(func(s domain.TypeSpec) string { return s.Proto }),
+		"ToOpenAPIType":  field(func(s domain.TypeSpec) string { return s.OpenAPI }),
+		"ToTestValue":    field(func(s domain.TypeSpec) string { return s.TestValue }),
+		"ToProto":        convert(func(s domain.TypeSpec) string { return s.ToProto }),
+		"FromProto":      convert(func(s domain.TypeSpec) string { return s.FromProto }),
+		"SQLArg":         convert(func(s domain.TypeSpec) string { return s.SQLWrap }),
+		"ZeroValue":      field(func(s domain.TypeSpec) string { return s.Zero }),
+		// ParseValue возвращает выражение разбора строки или пустую строку,
+		// если значение типа используется без преобразования
+		"ParseValue": func(name, expr string) (string, error) {
+			spec, err := registry().lookup(name)
+			if err != nil || spec.Parse == "" {
+				return "", err
+			}
+			return fmt.Sprintf(spec.Parse, expr), nil
+		},
+		// ToProtoError и FromProtoError сообщают, что преобразование типа
+		// возвращает значение и ошибку
+		"ToProtoError":   failing(func(s domain.TypeSpec) bool { return s.ToProtoError }),
+		"FromProtoError": failing(func(s domain.TypeSpec) bool { return s.FromProtoError }),
+		"ParseImports":   imports(func(s domain.TypeSpec) []string { return s.ParseImports }),
+		"GoImports":      imports(func(s domain.TypeSpec) []string { return s.Imports }),
+		"SQLImports":     imports(func(s domain.TypeSpec) []string { return s.SQLImports }),
+		"ProtoGoImports": imports(func(s domain.TypeSpec) []string { return s.ProtoGoImports }),
+		"ProtoImports": imports(func(s domain.TypeSpec) []string {
+			if s.ProtoImport == "" {
+				return nil
+			}
+			return []string{s.ProtoImport}
+		}),
+		"IsPointer": func(f domain.Field) (bool, error) {
+			spec, err := registry().lookup(f.Type)
+			return err == nil && isPointer(f, spec), err
+		},
+		"IsNullColumn": func(f domain.Field) (bool, error) {
+			spec, err := registry().lookup(f.Type)
+			return err == nil && (f.Nullable || isReference(spec.GoType)), err
+		},
+		// ProtoOptional сообщает, что поле объявляется в proto3 как optional:
+		// у сообщений и repeated-полей наличие значения различимо и без него
+		"ProtoOptional": func(f domain.Field) (bool, error) {
+			spec, err := registry().lookup(f.Type)
+			if err != nil {
+				return false, err
+			}
+			return isPointer(f, spec) && !strings.Contains(spec.Proto, ".") && !strings.HasPrefix(spec.Proto, "repeated "), nil
+		},
+		"FieldType": func(f domain.Field) (string, error) {
+			spec, err := registry().lookup(f.Type)
+			if err != nil {
+				return "", err
+			}
+			if isPointer(f, spec) {
+				return "*" + spec.GoType, nil
+			}
+			return spec.GoType, nil
+		},
+		// FieldTags возвращает теги поля структуры: json в lowerCamelCase и bson в snake_case, если они не заданы, binding по правилам проверки,
+		// enums со значениями перечисления, а для составных Go-типов swaggertype, чтобы swag описывал их по OpenAPI-типу
+		"FieldTags": func(f domain.Field) (string, error) {
+			spec, err := registry().lookup(f.Type)
+			if err != nil {
+				return "", err
+			}
+			tags := append([]string(nil), f.Tags...)
+			if !hasTag(tags, "json") {
+				tags = append([]string{fmt.Sprintf(`json:"%s"`, jsonName(f))}, tags...)
+			}
+			if !hasTag(tags, "bson") {
+				tags = append(tags, fmt.Sprintf(`bson:"%s"`, bsonName(f.Name)))
+			}
+			if f.Nullable {
+				tags = omitEmpty(tags)
+			}
+			if binding := bindingTag(f, spec); binding != "" && !hasTag(tags, "binding") {
+				tags = append(tags, fmt.Sprintf(`binding:"%s"`, binding))
+			}
+			if len(spec.Values) > 0 {
+				tags = append(tags, fmt.Sprintf(`enums:"%s"`, strings.Join(spec.Values, ",")))
+			}
+			if strings.Contains(spec.GoType, ".") && spec.OpenAPI != "" && spec.OpenAPI != "object" {
+				tags = append(tags, fmt.Sprintf(`swaggertype:"primitive,%s"`, spec.OpenAPI))
+				if spec.OpenAPIFormat != "" {
+					tags = append(tags, fmt.Sprintf(`format:"%s"`, spec.OpenAPIFormat))
+				}
+			}
+			if len(tags) == 0 {
+				return "", nil
+			}
+			return " `" + strings.Join(tags, " ") + "`", nil
+		},
+	}
+}
+
+// isReference сообщает, что нулевое значение Go-типа (nil) уже означает NULL
+func isReference(goType string) bool {
+	return strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[")
+}
+
+// isPointer сообщает, что nullable-поле представляется указателем
+func isPointer(f domain.Field, spec domain.TypeSpec) bool {
+	return f.Nullable && !isReference(spec.GoType)
+}
+
+// hasTag сообщает, что среди тегов поля есть тег с указанным ключом
+func hasTag(tags []string, key string) bool {
+	for _, tag := range tags {
+		if _, ok := reflect.StructTag(tag).Lookup(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// omitEmpty добавляет omitempty к json-тегу поля
+func omitEmpty(tags []string) []string {
+	for i, tag := range tags {
+		if strings.HasPrefix(tag, `json:"`) && !strings.Contains(tag, "omitempty") && !strings.HasPrefix(tag, `json:"-"`) {
+			tags[i] = strings.TrimSuffix(tag, `"`) + `,omitempty"`
+		}
+	}
+	return tags
+}
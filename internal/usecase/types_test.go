@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+func TestNewTypeRegistry(t *testing.T) {
+	tests := []struct {
+		name   string
+		custom domain.TypeSpec
+		want   domain.TypeSpec
+		err    string
+	}{
+		{
+			name:   "defaults",
+			custom: domain.TypeSpec{Name: "money", GoType: "decimal.Decimal", Imports: []string{"github.com/shopspring/decimal"}, Postgres: "NUMERIC(12,2)"},
+			want: domain.TypeSpec{
+				Name:           "money",
+				GoType:         "decimal.Decimal",
+				Imports:        []string{"github.com/shopspring/decimal"},
+				Postgres:       "NUMERIC(12,2)",
+				Mongo:          "object",
+				OpenAPI:        "object",
+				ProtoGoImports: []string{"github.com/shopspring/decimal"},
+				ParseImports:   []string{"github.com/shopspring/decimal"},
+			},
+		},
+		{
+			name:   "builtin override",
+			custom: domain.TypeSpec{Name: "int", GoType: "int32", Postgres: "SMALLINT", Mongo: "number", OpenAPI: "integer"},
+			want:   domain.TypeSpec{Name: "int", GoType: "int32", Postgres: "SMALLINT", Mongo: "number", OpenAPI: "integer"},
+		},
+		{
+			name:   "no name",
+			custom: domain.TypeSpec{GoType: "int32", Postgres: "SMALLINT"},
+			err:    "type name is required",
+		},
+		{
+			name:   "no go_type",
+			custom: domain.TypeSpec{Name: "money", Postgres: "NUMERIC(12,2)"},
+			err:    `type "money": go_type is required`,
+		},
+		{
+			name:   "no postgres",
+			custom: domain.TypeSpec{Name: "money", GoType: "decimal.Decimal"},
+			err:    `type "money": postgres is required`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := newTypeRegistry([]domain.TypeSpec{tt.custom})
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := registry.lookup(tt.want.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lookup(%q) = %+v, want %+v", tt.want.Name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeLookup(t *testing.T) {
+	registry, err := newTypeRegistry(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		goType   string
+		postgres string
+		err      string
+	}{
+		{name: "time", goType: "time.Time", postgres: "TIMESTAMPTZ"},
+		{name: "decimal", goType: "decimal.Decimal", postgres: "NUMERIC"},
+		{name: "[]string", goType: "[]string", postgres: "TEXT[]"},
+		{name: "[]int64", goType: "[]int64", postgres: "BIGINT[]"},
+		{name: "[]time", err: `arrays of "time" are not supported`},
+		{name: "money", err: `unknown type "money"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := registry.lookup(tt.name)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if spec.GoType != tt.goType || spec.Postgres != tt.postgres {
+				t.Errorf("lookup(%q) = %s, %s, want %s, %s", tt.name, spec.GoType, spec.Postgres, tt.goType, tt.postgres)
+			}
+		})
+	}
+}
+
+func TestFieldTypes(t *testing.T) {
+	config := testConfig(domain.Entity{Name: "Product", Fields: []domain.Field{
+		{Name: "Released", Type: "time"},
+		{Name: "Weight", Type: "decimal"},
+		{Name: "Price", Type: "money"},
+		{Name: "Count", Type: "int"},
+		{Name: "Tags", Type: "[]string"},
+	}})
+	config.Types = []domain.TypeSpec{
+		{
+			Name:           "money",
+			GoType:         "decimal.Decimal",
+			Imports:        []string{"github.com/shopspring/decimal"},
+			Postgres:       "NUMERIC(12,2)",
+			Mongo:          "decimal",
+			Proto:          "string",
+			ToProto:        "%s.String()",
+			FromProto:      "decimal.NewFromString(%s)",
+			FromProtoError: true,
+			OpenAPI:        "string",
+			TestValue:      "decimal.NewFromInt(10)",
+		},
+		// Тип с именем встроенного переопределяет его
+		{Name: "int", GoType: "int32", Postgres: "SMALLINT", Proto: "int32", TestValue: "1"},
+	}
+	files := renderTestProject(t, config)
+
+	want := map[string][]string{
+		"internal/domain/product.go": {
+			"Released time.Time `json:\"released\" bson:\"released\" swaggertype:\"primitive,string\" format:\"date-time\"`",
+			"Weight decimal.Decimal `json:\"weight\" bson:\"weight\" swaggertype:\"primitive,string\" format:\"decimal\"`",
+			"Price decimal.Decimal `json:\"price\" bson:\"price\" swaggertype:\"primitive,string\"`",
+			"Count int32 `json:\"count\" bson:\"count\"`",
+			"Tags []string `json:\"tags\" bson:\"tags\"`",
+		},
+		"migrations/postgres/001_create_product.up.sql": {
+			"released TIMESTAMPTZ NOT NULL,",
+			"weight NUMERIC NOT NULL,",
+			"price NUMERIC(12,2) NOT NULL,",
+			"count SMALLINT NOT NULL,",
+			"tags TEXT[],",
+		},
+		"proto/product.proto": {
+			"google.protobuf.Timestamp released = 2;",
+			"string weight = 3;",
+			"string price = 4;",
+			"int32 count = 5;",
+			"repeated string tags = 6;",
+		},
+		"pkg/grpc/controller/product.go": {
+			"if value, err := timeFromProto(req.GetReleased()); err != nil {",
+			"if value, err := decimal.NewFromString(req.GetPrice()); err != nil {",
+			`addFieldError(&errs, "price", err)`,
+			"entity.Count = req.GetCount()",
+		},
+	}
+	for path, lines := range want {
+		for _, line := range lines {
+			if !hasLine(files[path], line) {
+				t.Errorf("%s does not contain %q:\n%s", path, line, files[path])
+			}
+		}
+	}
+}
+
+func TestRenderRejectsUnknownType(t *testing.T) {
+	t.Chdir(t.TempDir())
+	config := testConfig(domain.Entity{Name: "Product", Fields: []domain.Field{{Name: "Price", Type: "money"}}})
+	_, err := NewGenerator().Render(config)
+	if err == nil || !strings.Contains(err.Error(), `unknown type "money"`) {
+		t.Errorf("Render() error = %v, want unknown type", err)
+	}
+}
@@ -0,0 +1,636 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+	"github.com/iancoleman/strcase"
+)
+
+type Validator interface {
+	// Validate проверяет конфигурацию и возвращает все найденные проблемы
+	Validate(config *domain.ProjectConfig) []domain.Diagnostic
+	// ValidateRaw дополнительно проверяет исходный JSON: синтаксис и неизвестные ключи
+	ValidateRaw(data []byte) []domain.Diagnostic
+}
+
+// ConfigError возвращается генератором, если конфигурация содержит ошибки
+type ConfigError struct {
+	Diagnostics []domain.Diagnostic
+}
+
+func (e *ConfigError) Error() string {
+	var lines []string
+	for _, d := range e.Diagnostics {
+		if d.Severity == domain.SeverityError {
+			lines = append(lines, FormatDiagnostic(d))
+		}
+	}
+	return "invalid config:\n  " + strings.Join(lines, "\n  ")
+}
+
+// FormatDiagnostic возвращает однострочное представление диагностики
+func FormatDiagnostic(d domain.Diagnostic) string {
+	line := fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	if d.Path != "" {
+		line = fmt.Sprintf("%s: %s: %s", d.Severity, d.Path, d.Message)
+	}
+	if d.Suggestion != "" {
+		line += " (" + d.Suggestion + ")"
+	}
+	return line
+}
+
+// HasErrors сообщает, есть ли среди диагностик ошибки
+func HasErrors(diagnostics []domain.Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == domain.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	supportedRepositories = []string{"postgres", "mongodb"}
+	supportedRelations    = []string{domain.RelationBelongsTo, domain.RelationHasMany, domain.RelationManyToMany}
+	supportedOnDelete     = []string{"CASCADE", "SET NULL", "SET DEFAULT", "RESTRICT", "NO ACTION"}
+
+	// Поля, которые генератор добавляет в каждую сущность сам
+	generatedFields = []string{"CreatedAt", "UpdatedAt"}
+	// Имена методов сгенерированной сущности
+	entityMethods = []string{"Validate"}
+
+	// Распространенные синонимы типов из других языков
+	typeAliases = map[string]string{
+		"integer":                "int64",
+		"long":                   "int64",
+		"float":                  "float64",
+		"double":                 "float64",
+		"number":                 "float64",
+		"boolean":                "bool",
+		"text":                   "string",
+		"varchar":                "string",
+		"str":                    "string",
+		"time.time":              "time",
+		"timestamp":              "time",
+		"timestamptz":            "time",
+		"datetime":               "time",
+		"date":                   "time",
+		"uuid.uuid":              "uuid",
+		"guid":                   "uuid",
+		"decimal.decimal":        "decimal",
+		"numeric":                "decimal",
+		"money":                  "decimal",
+		"jsonb":                  "json",
+		"object":                 "json",
+		"map":                    "json",
+		"map[string]any":         "json",
+		"map[string]interface{}": "json",
+		"[]byte":                 "bytes",
+		"bytea":                  "bytes",
+		"blob":                   "bytes",
+		"binary":                 "bytes",
+	}
+)
+
+type validator struct{}
+
+func NewValidator() Validator {
+	return &validator{}
+}
+
+func (v *validator) ValidateRaw(data []byte) []domain.Diagnostic {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return []domain.Diagnostic{jsonErrorDiagnostic(data, err)}
+	}
+
+	var diagnostics []domain.Diagnostic
+	diagnostics = append(diagnostics, unknownKeys(raw, reflect.TypeOf(domain.ProjectConfig{}), "")...)
+
+	var config domain.ProjectConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return append(diagnostics, jsonErrorDiagnostic(data, err))
+	}
+	canonicalizeNames(&config)
+
+	return append(diagnostics, v.Validate(&config)...)
+}
+
+func (v *validator) Validate(config *domain.ProjectConfig) []domain.Diagnostic {
+	var diagnostics []domain.Diagnostic
+	report := func(severity domain.Severity, path, message, suggestion string) {
+		diagnostics = append(diagnostics, domain.Diagnostic{
+			Path:       path,
+			Severity:   severity,
+			Message:    message,
+			Suggestion: suggestion,
+		})
+	}
+
+	if config.Name == "" {
+		report(domain.SeverityError, "name", "project name is required", `set "name" to the output directory name, e.g. "my-service"`)
+	} else if strings.ContainsAny(config.Name, `/\:*?"<>| `) {
+		report(domain.SeverityError, "name", fmt.Sprintf("project name %q is not a valid directory name", config.Name), fmt.Sprintf("use %q", strcase.ToKebab(config.Name)))
+	}
+
+	checkModule(report, config)
+
+	if config.Templates != "" {
+		if info, err := os.Stat(config.Templates); err != nil || !info.IsDir() {
+			report(domain.SeverityError, "templates", fmt.Sprintf("templates directory %q does not exist", config.Templates), "run `generator templates export` to create it")
+		}
+	}
+
+	if config.Port < 0 || config.Port > 65535 {
+		report(domain.SeverityError, "port", fmt.Sprintf("port %d is out of range", config.Port), "use a value between 1 and 65535 or omit it to pick a random port")
+	}
+
+	// Пользовательские типы регистрируем до проверки полей, которые на них ссылаются
+	types, _ := newTypeRegistry(nil)
+	typeNames := make(map[string]bool, len(config.Types))
+	for i, spec := range config.Types {
+		path := fmt.Sprintf("types[%d]", i)
+		if spec.Name != "" && typeNames[spec.Name] {
+			report(domain.SeverityError, path+".name", fmt.Sprintf("type %q is declared twice", spec.Name), "type names must be unique")
+			continue
+		}
+		typeNames[spec.Name] = true
+		if spec.Name == domain.EnumType {
+			report(domain.SeverityError, path+".name", fmt.Sprintf("type name %q is reserved for enum fields", spec.Name), "choose another name")
+			continue
+		}
+		if err := types.register(spec); err != nil {
+			report(domain.SeverityError, path, err.Error(), "")
+			continue
+		}
+		if spec.Proto == "" && config.Features.GRPC {
+			report(domain.SeverityError, path+".proto", fmt.Sprintf("type %q has no proto representation", spec.Name), "set \"proto\" or disable the grpc feature")
+		}
+		if spec.TestValue == "" && config.Features.Tests {
+			report(domain.SeverityError, path+".test_value", fmt.Sprintf("type %q has no test value", spec.Name), "set \"test_value\" to a Go expression of the type")
+		}
+		for _, op := range spec.Filter {
+			if !contains(filterOps, op) {
+				report(domain.SeverityError, path+".filter", fmt.Sprintf("type %q has unknown filter operator %q", spec.Name, op), "use "+strings.Join(filterOps, ", "))
+			}
+		}
+		if len(spec.Filter) > 0 && spec.GoType != "string" && spec.Parse == "" {
+			report(domain.SeverityError, path+".parse", fmt.Sprintf("type %q has filter operators but cannot be parsed from a query parameter", spec.Name), "set \"parse\" to an expression returning the value and an error")
+		}
+	}
+
+	checkObjects(report, types, config)
+
+	if len(config.Entities) == 0 {
+		report(domain.SeverityError, "entities", "at least one entity is required", "")
+	}
+
+	enumNames := make(map[string]string)
+	entities := make(map[string]domain.Entity, len(config.Entities))
+	entityKeys := make(map[string]domain.Field, len(config.Entities))
+	snakeNames := make(map[string]string, len(config.Entities))
+	for i, entity := range config.Entities {
+		path := fmt.Sprintf("entities[%d]", i)
+		checkIdentifier(report, path+".name", "entity", entity.Name)
+
+		if entity.Name != "" {
+			snake := strcase.ToSnake(entity.Name)
+			if other, ok := snakeNames[snake]; ok {
+				report(domain.SeverityError, path+".name", fmt.Sprintf("entity %q clashes with entity %q", entity.Name, other), "entity names must be unique")
+			} else {
+				snakeNames[snake] = entity.Name
+				entities[entity.Name] = entity
+			}
+		}
+
+		if len(entity.Fields) == 0 {
+			report(domain.SeverityWarning, path+".fields", fmt.Sprintf("entity %q has no fields", entity.Name), "")
+		}
+
+		fieldNames := make(map[string]bool, len(entity.Fields))
+		for j, field := range entity.Fields {
+			fieldPath := fmt.Sprintf("%s.fields[%d]", path, j)
+			checkIdentifier(report, fieldPath+".name", "field", field.Name)
+
+			if contains(generatedFields, field.Name) {
+				report(domain.SeverityError, fieldPath+".name", fmt.Sprintf("field %q duplicates the field generated for every entity", field.Name), "remove the field, the generator adds it automatically")
+			} else if entity.OptimisticLocking && field.Name == "Version" {
+				report(domain.SeverityError, fieldPath+".name", `field "Version" clashes with the version field added by optimistic_locking`, `rename the field or remove "optimistic_locking"`)
+			} else if contains(entityMethods, field.Name) {
+				report(domain.SeverityError, fieldPath+".name", fmt.Sprintf("field %q clashes with the generated method %s", field.Name, field.Name), "rename the field")
+			} else if fieldNames[field.Name] {
+				report(domain.SeverityError, fieldPath+".name", fmt.Sprintf("duplicate field %q", field.Name), "field names must be unique within an entity")
+			}
+			fieldNames[field.Name] = true
+
+			if field.Required && field.Nullable {
+				report(domain.SeverityError, fieldPath+".nullable", fmt.Sprintf("field %q cannot be both required and nullable", field.Name), `remove "required" or "nullable"`)
+			}
+
+			if len(field.Values) > 0 && field.Type != domain.EnumType {
+				report(domain.SeverityError, fieldPath+".values", fmt.Sprintf("values apply to enum fields, field %q has type %q", field.Name, field.Type), `set "type": "enum" or use "one_of"`)
+			}
+
+			if field.Type == "" {
+				report(domain.SeverityError, fieldPath+".type", fmt.Sprintf("field %q has no type", field.Name), "use one of: "+strings.Join(append(types.names(), domain.EnumType), ", "))
+			} else if field.Type == domain.EnumType {
+				checkEnum(report, fieldPath, field)
+				if len(field.Values) > 0 && entity.Name != "" {
+					enum := enumSpec(entity.Name, field)
+					if _, err := types.lookup(enum.Name); err == nil || isEntityName(config, enum.Name) {
+						report(domain.SeverityError, fieldPath+".name", fmt.Sprintf("enum type %q of field %q clashes with a declared type or entity", enum.Name, field.Name), "rename the field or the clashing type")
+					} else if other, ok := enumNames[enum.Name]; ok {
+						report(domain.SeverityError, fieldPath+".name", fmt.Sprintf("enum type %q of field %q clashes with enum field %s", enum.Name, field.Name, other), "rename one of the fields")
+					}
+					enumNames[enum.Name] = entity.Name + "." + field.Name
+					checkRules(report, enum, fieldPath, field)
+				}
+			} else if spec, err := types.lookup(field.Type); err != nil {
+				report(domain.SeverityError, fieldPath+".type", err.Error(), suggestType(types, field.Type))
+			} else {
+				if spec.Flatten && field.Nullable {
+					report(domain.SeverityError, fieldPath+".nullable", fmt.Sprintf("value object field %q stored in columns cannot be nullable", field.Name), fmt.Sprintf(`set "storage": "jsonb" on value object %q`, spec.Name))
+				}
+				checkRules(report, spec, fieldPath, field)
+			}
+		}
+
+		if entity.Pagination != "" && !contains(supportedPaginations, entity.Pagination) {
+			report(domain.SeverityError, path+".pagination", fmt.Sprintf("unknown pagination mode %q", entity.Pagination), suggestOneOf(entity.Pagination, supportedPaginations))
+		}
+
+		if entity.ID != nil && entity.ID.Strategy != "" && !contains(supportedStrategies, entity.ID.Strategy) {
+			report(domain.SeverityError, path+".id.strategy", fmt.Sprintf("unknown id strategy %q", entity.ID.Strategy), suggestOneOf(entity.ID.Strategy, supportedStrategies))
+		} else if strategy, key, err := entityKey(entity); err != nil {
+			report(domain.SeverityError, path+".id", err.Error(), "")
+		} else {
+			entityKeys[entity.Name] = key
+			checkKey(report, types, config, path, strategy, key)
+		}
+	}
+
+	checkNames(report, config)
+
+	// Связи проверяем после того, как собраны все имена сущностей
+	for i, entity := range config.Entities {
+		for j, rel := range entity.Relations {
+			path := fmt.Sprintf("entities[%d].relations[%d]", i, j)
+
+			if !contains(supportedRelations, rel.Type) {
+				report(domain.SeverityError, path+".type", fmt.Sprintf("unknown relation type %q", rel.Type), suggestOneOf(rel.Type, supportedRelations))
+			}
+
+			target, ok := entities[rel.Entity]
+			if !ok {
+				report(domain.SeverityError, path+".entity", fmt.Sprintf("relation to unknown entity %q", rel.Entity), suggestOneOf(rel.Entity, keys(entities)))
+				continue
+			}
+
+			if rel.OnDelete != "" && !contains(supportedOnDelete, strings.ToUpper(rel.OnDelete)) {
+				report(domain.SeverityError, path+".on_delete", fmt.Sprintf("unknown ON DELETE action %q", rel.OnDelete), "use one of: "+strings.Join(supportedOnDelete, ", "))
+			}
+
+			switch rel.Type {
+			case domain.RelationBelongsTo, domain.RelationHasMany:
+				owner := entity
+				if rel.Type == domain.RelationHasMany {
+					owner = target
+				}
+				parent, foreignKey := target, rel.ForeignKey
+				if rel.Type == domain.RelationHasMany {
+					parent = entity
+				}
+				if foreignKey != "" {
+					checkIdentifier(report, path+".foreign_key", "foreign key", foreignKey)
+				} else {
+					foreignKey = strcase.ToCamel(parent.Name) + "ID"
+				}
+				key, ok := entityKeys[parent.Name]
+				for _, field := range owner.Fields {
+					if field.Name != foreignKey {
+						continue
+					}
+					if ok && field.Type != key.Type {
+						report(domain.SeverityError, path+".foreign_key", fmt.Sprintf("foreign key field %q of entity %q must have type %q of the %s key", field.Name, owner.Name, key.Type, parent.Name), fmt.Sprintf("change the field type to %q or remove the field", key.Type))
+					}
+					if strings.EqualFold(rel.OnDelete, "SET NULL") && !field.Nullable {
+						report(domain.SeverityError, path+".on_delete", fmt.Sprintf("ON DELETE SET NULL requires foreign key field %q of entity %q to be nullable", field.Name, owner.Name), `set "nullable": true on the field or remove the field`)
+					}
+				}
+			case domain.RelationManyToMany:
+				if rel.Entity == entity.Name {
+					report(domain.SeverityError, path+".entity", "many_to_many relation to itself is not supported", "introduce a separate link entity with two belongs_to relations")
+				}
+			}
+		}
+	}
+
+	repositories := make(map[string]bool, len(config.Repositories))
+	for i, repo := range config.Repositories {
+		path := fmt.Sprintf("repositories[%d]", i)
+		if !contains(supportedRepositories, repo) {
+			report(domain.SeverityError, path, fmt.Sprintf("unsupported repository %q", repo), suggestOneOf(repo, supportedRepositories))
+		} else if repositories[repo] {
+			report(domain.SeverityWarning, path, fmt.Sprintf("repository %q is listed twice", repo), "remove the duplicate")
+		}
+		repositories[repo] = true
+	}
+	if len(config.Repositories) == 0 {
+		report(domain.SeverityWarning, "repositories", "no repositories selected, storage code will not be generated", `add "postgres" and/or "mongodb"`)
+	}
+
+	return diagnostics
+}
+
+// checkRules проверяет, что правила проверки поля применимы к его типу и не
+// противоречат друг другу
+func checkRules(report func(domain.Severity, string, string, string), spec domain.TypeSpec, path string, field domain.Field) {
+	if field.Required && requiredCheck(spec, "v") == "" {
+		report(domain.SeverityWarning, path+".required", fmt.Sprintf("required has no effect on field %q of type %q", field.Name, field.Type), `remove "required"`)
+	}
+
+	numeric := map[string]*float64{"min": field.Min, "max": field.Max}
+	for _, rule := range []string{"min", "max"} {
+		value := numeric[rule]
+		switch {
+		case value == nil:
+		case !isNumber(spec):
+			report(domain.SeverityError, path+"."+rule, fmt.Sprintf("%s applies to numeric fields, field %q has type %q", rule, field.Name, field.Type), "use min_length/max_length for strings")
+		case isInteger(spec) && *value != math.Trunc(*value):
+			report(domain.SeverityError, path+"."+rule, fmt.Sprintf("%s of integer field %q must be a whole number", rule, field.Name), fmt.Sprintf("use %s", formatNumber(math.Trunc(*value))))
+		}
+	}
+	if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+		report(domain.SeverityError, path+".min", fmt.Sprintf("min %s of field %q is greater than max %s", formatNumber(*field.Min), field.Name, formatNumber(*field.Max)), "")
+	}
+
+	text := map[string]bool{
+		"min_length": field.MinLength != nil,
+		"max_length": field.MaxLength != nil,
+		"pattern":    field.Pattern != "",
+		"email":      field.Email,
+		"url":        field.URL,
+		"one_of":     len(field.OneOf) > 0,
+	}
+	for _, rule := range []string{"min_length", "max_length", "pattern", "email", "url", "one_of"} {
+		if text[rule] && !isText(spec) {
+			report(domain.SeverityError, path+"."+rule, fmt.Sprintf("%s applies to string fields, field %q has type %q", rule, field.Name, field.Type), "")
+		}
+	}
+	lengths := map[string]*int{"min_length": field.MinLength, "max_length": field.MaxLength}
+	for _, rule := range []string{"min_length", "max_length"} {
+		if length := lengths[rule]; length != nil && *length < 0 {
+			report(domain.SeverityError, path+"."+rule, fmt.Sprintf("%s of field %q cannot be negative", rule, field.Name), "")
+		}
+	}
+	if field.MinLength != nil && field.MaxLength != nil && *field.MinLength > *field.MaxLength {
+		report(domain.SeverityError, path+".min_length", fmt.Sprintf("min_length %d of field %q is greater than max_length %d", *field.MinLength, field.Name, *field.MaxLength), "")
+	}
+	if field.Pattern != "" {
+		if _, err := regexp.Compile(field.Pattern); err != nil {
+			report(domain.SeverityError, path+".pattern", fmt.Sprintf("invalid pattern of field %q: %v", field.Name, err), "use RE2 syntax")
+		}
+	}
+}
+
+// checkKey проверяет, что первичный ключ сущности поддерживается выбранными
+// хранилищами и может быть разобран из параметра пути
+func checkKey(report func(domain.Severity, string, string, string), types *typeRegistry, config *domain.ProjectConfig, path, strategy string, key domain.Field) {
+	if dbGenerated(strategy) && contains(config.Repositories, "mongodb") {
+		report(domain.SeverityError, path+".id.strategy", fmt.Sprintf("%s keys are assigned by Postgres and are not supported by the mongodb repository", strategy), "use uuid, uuid7, ulid or snowflake")
+	}
+	if strategy != domain.IDNatural {
+		return
+	}
+
+	fieldPath := path + ".id.field"
+	spec, err := types.lookup(key.Type)
+	switch {
+	case key.Type == domain.EnumType:
+		report(domain.SeverityError, fieldPath, fmt.Sprintf("natural key field %q cannot be an enum", key.Name), "use a string, integer, uuid or ulid field")
+	case err != nil:
+		// Неизвестный тип уже отмечен в проверке полей
+	case key.Nullable:
+		report(domain.SeverityError, fieldPath, fmt.Sprintf("natural key field %q cannot be nullable", key.Name), `remove "nullable" from the field`)
+	case spec.Zero == "":
+		report(domain.SeverityError, fieldPath, fmt.Sprintf("natural key field %q of type %q has no zero value to check for a missing key", key.Name, key.Type), "use a string, integer, uuid or ulid field")
+	case spec.GoType != "string" && spec.Parse == "":
+		report(domain.SeverityError, fieldPath, fmt.Sprintf("natural key field %q of type %q cannot be parsed from a path parameter", key.Name, key.Type), "use a string, integer, uuid or ulid field")
+	}
+}
+
+// isEntityName сообщает, что в конфигурации объявлена сущность с таким именем
+func isEntityName(config *domain.ProjectConfig, name string) bool {
+	for _, entity := range config.Entities {
+		if entity.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func checkIdentifier(report func(domain.Severity, string, string, string), path, kind, name string) {
+	switch {
+	case name == "":
+		report(domain.SeverityError, path, kind+" name is required", "")
+	case !token.IsIdentifier(name):
+		suggestion := ""
+		if camel := strcase.ToCamel(name); token.IsIdentifier(camel) {
+			suggestion = fmt.Sprintf("use %q", camel)
+		}
+		report(domain.SeverityError, path, fmt.Sprintf("%s name %q is not a valid Go identifier", kind, name), suggestion)
+	case !token.IsExported(name):
+		report(domain.SeverityError, path, fmt.Sprintf("%s name %q is lowercase and would produce an unexported Go identifier", kind, name), fmt.Sprintf("use %q", strcase.ToCamel(name)))
+	}
+}
+
+func suggestType(types *typeRegistry, name string) string {
+	if alias, ok := typeAliases[strings.ToLower(name)]; ok {
+		return fmt.Sprintf("use %q", alias)
+	}
+	if strings.HasPrefix(name, "[]") {
+		arrays := make([]string, len(arrayElements))
+		for i, elem := range arrayElements {
+			arrays[i] = "[]" + elem
+		}
+		return "use one of: " + strings.Join(arrays, ", ")
+	}
+	return suggestOneOf(name, types.names())
+}
+
+// suggestOneOf предлагает ближайшее по расстоянию Левенштейна значение,
+// а если подходящего нет — перечисляет все допустимые
+func suggestOneOf(name string, candidates []string) string {
+	best, bestDistance := "", 0
+	for _, candidate := range candidates {
+		distance := levenshtein(strings.ToLower(name), strings.ToLower(candidate))
+		if best == "" || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	if bestDistance > len(best)/2+1 {
+		return "use one of: " + strings.Join(candidates, ", ")
+	}
+	return fmt.Sprintf("did you mean %q?", best)
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// unknownKeys находит ключи JSON, которым нет соответствия в структурах конфигурации
+func unknownKeys(raw interface{}, t reflect.Type, path string) []domain.Diagnostic {
+	var diagnostics []domain.Diagnostic
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+			if name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			keyPath := joinPath(path, name)
+			fieldType, ok := fields[name]
+			if !ok {
+				diagnostics = append(diagnostics, domain.Diagnostic{
+					Path:       keyPath,
+					Severity:   domain.SeverityWarning,
+					Message:    fmt.Sprintf("unknown key %q is ignored", name),
+					Suggestion: suggestOneOf(name, keysOf(fields)),
+				})
+				continue
+			}
+			diagnostics = append(diagnostics, unknownKeys(object[name], fieldType, keyPath)...)
+		}
+	case reflect.Slice:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i, item := range items {
+			diagnostics = append(diagnostics, unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return diagnostics
+}
+
+// indexPattern находит индексы элементов в пути json.UnmarshalTypeError
+// (entities.0.fields), чтобы привести его к виду entities[0].fields
+var indexPattern = regexp.MustCompile(`\.(\d+)\b`)
+
+// jsonErrorDiagnostic переводит ошибку разбора JSON в диагностику с номером строки
+func jsonErrorDiagnostic(data []byte, err error) domain.Diagnostic {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, column := position(data, syntaxErr.Offset)
+		return domain.Diagnostic{
+			Severity: domain.SeverityError,
+			Message:  fmt.Sprintf("invalid JSON at line %d, column %d: %v", line, column, err),
+		}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		line, column := position(data, typeErr.Offset)
+		return domain.Diagnostic{
+			Path:     indexPattern.ReplaceAllString(typeErr.Field, "[$1]"),
+			Severity: domain.SeverityError,
+			Message:  fmt.Sprintf("expected %s but got JSON %s at line %d, column %d", typeErr.Type, typeErr.Value, line, column),
+		}
+	}
+
+	return domain.Diagnostic{Severity: domain.SeverityError, Message: err.Error()}
+}
+
+func position(data []byte, offset int64) (line, column int) {
+	line, column = 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func keys(entities map[string]domain.Entity) []string {
+	names := make([]string, 0, len(entities))
+	for name := range entities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func keysOf(fields map[string]reflect.Type) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+)
+
+func TestValidateRaw(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		path       string
+		severity   domain.Severity
+		message    string
+		suggestion string
+	}{
+		{
+			name:       "unknown top-level key",
+			config:     `{"name": "shop", "module": "shop", "modul": "x", "entities": [{"name": "User", "fields": [{"name": "Email", "type": "string"}]}]}`,
+			path:       "modul",
+			severity:   domain.SeverityWarning,
+			message:    `unknown key "modul" is ignored`,
+			suggestion: `did you mean "module"?`,
+		},
+		{
+			name:       "unknown nested key",
+			config:     `{"name": "shop", "module": "shop", "entities": [{"name": "User", "fieldz": [], "fields": [{"name": "Email", "type": "string"}]}]}`,
+			path:       "entities[0].fieldz",
+			severity:   domain.SeverityWarning,
+			message:    `unknown key "fieldz" is ignored`,
+			suggestion: `did you mean "fields"?`,
+		},
+		{
+			name:     "type error",
+			config:   "{\n  \"name\": \"shop\",\n  \"module\": \"shop\",\n  \"port\": \"8080\"\n}",
+			path:     "port",
+			severity: domain.SeverityError,
+			message:  "expected int but got JSON string at line 4",
+		},
+		{
+			name:     "nested type error",
+			config:   `{"name": "shop", "module": "shop", "entities": [{"name": "User", "fields": [{"name": "Email", "type": "string", "required": "yes"}]}]}`,
+			path:     "entities[0].fields[0].required",
+			severity: domain.SeverityError,
+			message:  "expected bool but got JSON string",
+		},
+		{
+			name:     "syntax error",
+			config:   "{\n  \"name\": \"shop\",\n  \"module\": \"shop\"\n  \"port\": 8080\n}",
+			severity: domain.SeverityError,
+			message:  "invalid JSON at line 4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := NewValidator().ValidateRaw([]byte(tt.config))
+			for _, d := range diagnostics {
+				if d.Path != tt.path || d.Severity != tt.severity || !strings.Contains(d.Message, tt.message) {
+					continue
+				}
+				if d.Suggestion != tt.suggestion {
+					t.Errorf("suggestion = %q, want %q", d.Suggestion, tt.suggestion)
+				}
+				return
+			}
+			t.Errorf("no %s diagnostic at %q containing %q in %+v", tt.severity, tt.path, tt.message, diagnostics)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/KulikovAR/nibelungo-crud-generator/internal/domain"
+	"golang.org/x/tools/go/packages"
+)
+
+// FormatBuildError возвращает однострочное представление ошибки компиляции
+func FormatBuildError(e domain.BuildError) string {
+	line := e.Message
+	if e.File != "" {
+		line = fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	}
+	var source []string
+	if e.Template != "" {
+		source = append(source, "template "+e.Template)
+	}
+	if e.Entity != "" {
+		source = append(source, "entity "+e.Entity)
+	}
+	if len(source) > 0 {
+		line += " (" + strings.Join(source, ", ") + ")"
+	}
+	return line
+}
+
+func (g *generator) Verify(files *domain.FileSet) ([]domain.BuildError, error) {
+	root, err := filepath.Abs(files.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	// Проверка работает без сети: зависимости берутся из кеша модулей или
+	// vendor. go.mod и go.sum, которые при этом дополняет go, восстанавливаются
+	restore, err := preserveFiles(root, "go.mod", "go.sum")
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+
+	mod := "-mod=mod"
+	if _, err := os.Stat(filepath.Join(root, "vendor", "modules.txt")); err == nil {
+		mod = "-mod=vendor"
+	}
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax,
+		Dir:  root,
+		Env:  append(os.Environ(), "GOFLAGS="+mod, "GOPROXY=off", "GOWORK=off"),
+		// Тесты проверяются вместе с пакетами
+		Tests: true,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	var errs []domain.BuildError
+	seen := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			// Пакет и его тестовый вариант сообщают одни и те же ошибки
+			if key := e.Pos + e.Msg; !seen[key] {
+				seen[key] = true
+				errs = append(errs, mapBuildError(files, root, e))
+			}
+		}
+	}
+	return errs, nil
+}
+
+// mapBuildError сопоставляет ошибку пакета с файлом проекта, его шаблоном и сущностью
+func mapBuildError(files *domain.FileSet, root string, e packages.Error) domain.BuildError {
+	path, line, column := splitPosition(e.Pos)
+	if path == "" {
+		return domain.BuildError{Message: e.Msg}
+	}
+	if rel, err := filepath.Rel(root, path); err == nil {
+		path = filepath.ToSlash(rel)
+	}
+	if file, ok := files.Get(path); ok {
+		return buildError(file, line, column, e.Msg)
+	}
+	return domain.BuildError{File: filepath.Join(files.Root, path), Line: line, Column: column, Message: e.Msg}
+}
+
+func buildError(file *domain.GeneratedFile, line, column int, message string) domain.BuildError {
+	return domain.BuildError{
+		File:     file.Path,
+		Line:     line,
+		Column:   column,
+		Message:  message,
+		Template: file.Template,
+		Entity:   file.Entity,
+	}
+}
+
+// splitPosition разбирает позицию вида file:line:column или file:line
+func splitPosition(pos string) (path string, line, column int) {
+	parts := strings.Split(pos, ":")
+	var numbers []int
+	for len(parts) > 1 && len(numbers) < 2 {
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		numbers = append([]int{n}, numbers...)
+		parts = parts[:len(parts)-1]
+	}
+	if len(numbers) == 0 {
+		return "", 0, 0
+	}
+	line = numbers[0]
+	if len(numbers) > 1 {
+		column = numbers[1]
+	}
+	return strings.Join(parts, ":"), line, column
+}
+
+// preserveFiles запоминает файлы каталога и возвращает функцию, которая
+// возвращает их к запомненному состоянию: восстанавливает содержимое или
+// удаляет файлы, которых не было
+func preserveFiles(dir string, names ...string) (func(), error) {
+	saved := make(map[string][]byte, len(names))
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		switch {
+		case err == nil:
+			saved[name] = content
+		case !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+	return func() {
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			if content, ok := saved[name]; ok {
+				os.WriteFile(path, content, 0644)
+			} else {
+				os.Remove(path)
+			}
+		}
+	}, nil
+}